@@ -0,0 +1,82 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	internal "github.com/electrocucaracha/nephioadm/internal/app"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func NewApplyCommand(provider internal.Provider) *cobra.Command {
+	var globalOpts GlobalOptions
+
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Run this command in order to set up a fleet of management and workload Clusters",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("filename")
+			joinConcurrency, _ := cmd.Flags().GetInt("join-concurrency")
+
+			defaults, err := globalOpts.runnerOptions()
+			if err != nil {
+				return err
+			}
+
+			opts := &internal.FleetOptions{
+				File:            file,
+				Defaults:        *defaults,
+				JoinConcurrency: joinConcurrency,
+			}
+
+			return withTracing(cmd, &globalOpts, &opts.Defaults, func() error {
+				results, err := provider.Apply(cmd.Context(), opts)
+
+				writer := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 3, ' ', 0)
+				fmt.Fprintln(writer, "CLUSTER\tCONTEXT\tPHASE\tSTATUS\tDURATION\tERROR")
+
+				for _, result := range results {
+					message := ""
+					if result.Err != nil {
+						message = result.Err.Error()
+					}
+
+					fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n", result.Name, result.Context, result.Phase,
+						result.Outcome, result.Duration, message)
+				}
+
+				if flushErr := writer.Flush(); flushErr != nil {
+					return flushErr
+				}
+
+				return errors.Wrap(err, "failed to apply the nephio fleet")
+			})
+		},
+	}
+
+	cmd.Flags().StringP("filename", "f", "", "File describing the management and workload clusters of the fleet")
+	cmd.Flags().Int("join-concurrency", 0,
+		"Maximum number of workload clusters joined concurrently (0 joins all of them at the same time)")
+	_ = cmd.MarkFlagRequired("filename")
+
+	cmd = GetCommandFlags(cmd, &globalOpts)
+
+	return cmd
+}
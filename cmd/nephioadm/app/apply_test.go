@@ -0,0 +1,90 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app_test
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/electrocucaracha/nephioadm/cmd/nephioadm/app"
+	internal "github.com/electrocucaracha/nephioadm/internal/app"
+	"github.com/electrocucaracha/nephioadm/internal/kpt"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func (m *mock) Apply(ctx context.Context, opts *internal.FleetOptions) ([]internal.ClusterResult, error) {
+	m.FleetOpts = opts
+
+	return []internal.ClusterResult{
+		{Name: "management", Context: "kind-mgmt", Phase: internal.PhaseInit, Outcome: internal.OutcomePassed},
+		{Name: "edge", Context: "kind-edge", Phase: internal.PhaseJoin, Outcome: internal.OutcomePassed},
+	}, nil
+}
+
+var _ = Describe("Apply Command", func() {
+	var provider mock
+	var cmd *cobra.Command
+	var out *bytes.Buffer
+	testData := &internal.FleetOptions{
+		File: "/tmp/fleet.yaml",
+		Defaults: internal.NephioRunnerOptions{
+			BasePath:         "/tmp",
+			NephioRepoURI:    internal.DefaultNephioRepoURI,
+			GitServiceURI:    "http://gitea:3000/nephio-test",
+			SkipVerify:       true,
+			VerifyTimeout:    internal.DefaultVerifyTimeout,
+			ReconcileTimeout: internal.DefaultReconcileTimeout,
+			Parallelism:      internal.DefaultParallelism,
+			RetryPolicies:    kpt.DefaultRetryPolicies(),
+			WriteLogs:        true,
+			TracerProvider:   trace.NewNoopTracerProvider(),
+		},
+		JoinConcurrency: 2,
+	}
+
+	BeforeEach(func() {
+		provider = mock{}
+		out = new(bytes.Buffer)
+		cmd = app.NewApplyCommand(&provider)
+		cmd.SetOut(out)
+	})
+
+	DescribeTable("apply execution process", func(shouldSucceed bool, args ...string) {
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+
+		if shouldSucceed {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.String()).To(ContainSubstring("kind-edge"))
+			Expect(testData).To(Equal(provider.FleetOpts))
+		} else {
+			Expect(err).To(HaveOccurred())
+		}
+	},
+		Entry("when all options are defined", true,
+			"-f", testData.File,
+			"--join-concurrency", "2",
+			"--base-path", testData.Defaults.BasePath,
+			"--git-service", testData.Defaults.GitServiceURI,
+			"--skip-verify"),
+		Entry("when the fleet file isn't provided", false),
+		Entry("when invalid option is provided", false, "-f", testData.File, "--invalid"),
+	)
+})
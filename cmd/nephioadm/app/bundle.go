@@ -0,0 +1,71 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	internal "github.com/electrocucaracha/nephioadm/internal/app"
+	"github.com/electrocucaracha/nephioadm/internal/kpt"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func NewBundleCommand(provider internal.Provider) *cobra.Command {
+	var opts internal.BundleOptions
+
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Run this command in order to manage the offline bundles of the Nephio packages",
+	}
+
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create an archive with the Nephio packages, their lock data, images and checksums",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.RetryPolicies = kpt.DefaultRetryPolicies()
+
+			manifest, err := provider.CreateBundle(cmd.Context(), &opts)
+			if err != nil {
+				return errors.Wrap(err, "failed to create the nephio bundle")
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Bundle written to %s\n", opts.Output)
+
+			for _, pkg := range manifest.Packages {
+				fmt.Fprintf(out, "Package %s: %s\n", pkg.Path, pkg.Commit)
+			}
+
+			fmt.Fprintf(out, "Function images: %s\n", strings.Join(manifest.Images.Functions, ", "))
+			fmt.Fprintf(out, "Container images: %s\n", strings.Join(manifest.Images.Containers, ", "))
+
+			return nil
+		},
+	}
+
+	createCmd.Flags().StringVar(&opts.Output, "output", "nephio-bundle.tar.gz", "Gzip tarball the bundle is written to")
+	createCmd.Flags().StringVar(&opts.NephioRepoURI, "nephio-repo", internal.DefaultNephioRepoURI,
+		"URI of a git repository, local directory or tarball (file://) containing Nephio's packages")
+	createCmd.Flags().StringVar(&opts.Ref, "ref", "", "Git reference the packages are pinned to (default branch)")
+	createCmd.Flags().StringVar(&opts.CacheDir, "cache-dir", "", "Directory where the fetched packages are cached")
+
+	cmd.AddCommand(createCmd)
+
+	return cmd
+}
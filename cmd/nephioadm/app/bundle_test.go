@@ -0,0 +1,81 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app_test
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/electrocucaracha/nephioadm/cmd/nephioadm/app"
+	internal "github.com/electrocucaracha/nephioadm/internal/app"
+	"github.com/electrocucaracha/nephioadm/internal/bundle"
+	"github.com/electrocucaracha/nephioadm/internal/kpt"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+)
+
+func (m *mock) CreateBundle(ctx context.Context, opts *internal.BundleOptions) (*bundle.Manifest, error) {
+	m.BundleCreateOpts = opts
+
+	return &bundle.Manifest{
+		Packages: []bundle.Package{{Path: "nephio-system", Commit: "0123456789abcdef0123456789abcdef01234567"}},
+		Images:   bundle.Images{Functions: []string{"gcr.io/kpt-fn/search-replace:v0.2"}},
+	}, nil
+}
+
+var _ = Describe("Bundle Command", func() {
+	var provider mock
+	var cmd *cobra.Command
+	var out *bytes.Buffer
+
+	BeforeEach(func() {
+		provider = mock{}
+		out = new(bytes.Buffer)
+		cmd = app.NewBundleCommand(&provider)
+		cmd.SetOut(out)
+	})
+
+	DescribeTable("bundle execution process", func(expected *internal.BundleOptions, args ...string) {
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+
+		if expected != nil {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.String()).To(ContainSubstring("nephio-system: 0123456789abcdef0123456789abcdef01234567"))
+			Expect(out.String()).To(ContainSubstring("gcr.io/kpt-fn/search-replace:v0.2"))
+			Expect(provider.BundleCreateOpts).To(Equal(expected))
+		} else {
+			Expect(err).To(HaveOccurred())
+		}
+	},
+		Entry("when the default options are provided", &internal.BundleOptions{
+			Output:        "nephio-bundle.tar.gz",
+			NephioRepoURI: internal.DefaultNephioRepoURI,
+			RetryPolicies: kpt.DefaultRetryPolicies(),
+		}, "create"),
+		Entry("when all options are defined", &internal.BundleOptions{
+			Output:        "/tmp/bundle.tar.gz",
+			NephioRepoURI: "http://gitea:3000/playground/test.git",
+			Ref:           "v1.0.1",
+			CacheDir:      "/var/cache/nephioadm",
+			RetryPolicies: kpt.DefaultRetryPolicies(),
+		}, "create", "--output", "/tmp/bundle.tar.gz", "--nephio-repo", "http://gitea:3000/playground/test.git",
+			"--ref", "v1.0.1", "--cache-dir", "/var/cache/nephioadm"),
+		Entry("when invalid option is provided", nil, "create", "--invalid"),
+	)
+})
@@ -0,0 +1,112 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	internal "github.com/electrocucaracha/nephioadm/internal/app"
+	"github.com/electrocucaracha/nephioadm/internal/cache"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// shortLength is the number of characters printed of the cache keys and commits.
+const shortLength = 12
+
+func NewCacheCommand(provider internal.Provider) *cobra.Command {
+	var opts internal.CacheOptions
+
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Run this command in order to manage the cached Nephio packages",
+	}
+
+	cmd.PersistentFlags().StringVar(&opts.Dir, "cache-dir", "", "Directory where the fetched packages are cached")
+	_ = cmd.MarkPersistentFlagRequired("cache-dir")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the cached packages",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := provider.ListCache(cmd.Context(), &opts)
+			if err != nil {
+				return errors.Wrap(err, "failed to list the cached packages")
+			}
+
+			return printCacheEntries(cmd.OutOrStdout(), entries)
+		},
+	}
+
+	pruneCmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove the cached packages",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := provider.PruneCache(cmd.Context(), &opts)
+			if err != nil {
+				return errors.Wrap(err, "failed to prune the cached packages")
+			}
+
+			return printCacheEntries(cmd.OutOrStdout(), entries)
+		},
+	}
+
+	pruneCmd.Flags().DurationVar(&opts.UnusedFor, "unused-for", 0,
+		"Remove only the packages which haven't been used for longer than this duration, e.g. 720h")
+
+	cmd.AddCommand(listCmd, pruneCmd)
+
+	return cmd
+}
+
+func printCacheEntries(out io.Writer, entries []cache.Entry) error {
+	writer := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(writer, "KEY\tREPOSITORY\tPACKAGE\tREF\tCOMMIT\tSIZE\tLAST USED")
+
+	for _, entry := range entries {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", shorten(entry.Key), entry.RepoURI, entry.Path, entry.Ref,
+			shorten(entry.Commit), formatSize(entry.Size), entry.LastUsed.Local().Format(time.RFC3339))
+	}
+
+	return writer.Flush()
+}
+
+func shorten(value string) string {
+	if len(value) > shortLength {
+		return value[:shortLength]
+	}
+
+	return value
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+
+	value, prefix := float64(size)/unit, 0
+	for value >= unit && prefix < 3 {
+		value /= unit
+		prefix++
+	}
+
+	return fmt.Sprintf("%.1f%ciB", value, "KMGT"[prefix])
+}
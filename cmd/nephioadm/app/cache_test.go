@@ -0,0 +1,87 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/electrocucaracha/nephioadm/cmd/nephioadm/app"
+	internal "github.com/electrocucaracha/nephioadm/internal/app"
+	"github.com/electrocucaracha/nephioadm/internal/cache"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+)
+
+var cachedEntries = []cache.Entry{{
+	Key:      cache.Key(internal.DefaultNephioRepoURI, "nephio-system", "0123456789abcdef0123456789abcdef01234567"),
+	RepoURI:  internal.DefaultNephioRepoURI,
+	Path:     "nephio-system",
+	Commit:   "0123456789abcdef0123456789abcdef01234567",
+	Size:     3 * 1024,
+	LastUsed: time.Now(),
+}}
+
+func (m *mock) ListCache(ctx context.Context, opts *internal.CacheOptions) ([]cache.Entry, error) {
+	m.CacheOpts = opts
+
+	return cachedEntries, nil
+}
+
+func (m *mock) PruneCache(ctx context.Context, opts *internal.CacheOptions) ([]cache.Entry, error) {
+	m.CacheOpts = opts
+
+	return cachedEntries, nil
+}
+
+var _ = Describe("Cache Command", func() {
+	var provider mock
+	var cmd *cobra.Command
+	var out *bytes.Buffer
+
+	BeforeEach(func() {
+		provider = mock{}
+		out = new(bytes.Buffer)
+		cmd = app.NewCacheCommand(&provider)
+		cmd.SetOut(out)
+	})
+
+	DescribeTable("cache execution process", func(expected *internal.CacheOptions, args ...string) {
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+
+		if expected != nil {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.String()).To(ContainSubstring("nephio-system"))
+			Expect(out.String()).To(ContainSubstring("0123456789ab "))
+			Expect(out.String()).To(ContainSubstring("3.0KiB"))
+			Expect(provider.CacheOpts).To(Equal(expected))
+		} else {
+			Expect(err).To(HaveOccurred())
+		}
+	},
+		Entry("when the cached packages are listed", &internal.CacheOptions{Dir: "/var/cache/nephioadm"},
+			"list", "--cache-dir", "/var/cache/nephioadm"),
+		Entry("when the unused cached packages are pruned",
+			&internal.CacheOptions{Dir: "/var/cache/nephioadm", UnusedFor: 720 * time.Hour},
+			"prune", "--cache-dir", "/var/cache/nephioadm", "--unused-for", "720h"),
+		Entry("when the cache directory isn't provided", nil, "list"),
+		Entry("when invalid option is provided", nil, "list", "--cache-dir", "/tmp", "--invalid"),
+	)
+})
@@ -0,0 +1,70 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	internal "github.com/electrocucaracha/nephioadm/internal/app"
+	"github.com/electrocucaracha/nephioadm/internal/k8s"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func NewClustersCommand(provider internal.Provider) *cobra.Command {
+	var opts k8s.ClusterOptions
+
+	cmd := &cobra.Command{
+		Use:   "clusters",
+		Short: "Run this command in order to list the Clusters joined to the Nephio control plane",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusters, err := provider.ListClusters(cmd.Context(), &opts)
+			if err != nil {
+				return errors.Wrap(err, "failed to list the joined clusters")
+			}
+
+			writer := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 3, ' ', 0)
+			fmt.Fprintln(writer, "NAME\tREGION\tREPOSITORY\tLABELS")
+
+			for _, cluster := range clusters {
+				fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", cluster.Name, cluster.Region, cluster.Repo,
+					formatLabels(cluster.Labels))
+			}
+
+			return writer.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Kubeconfig, "kubeconfig", "", "Kubeconfig file of the management cluster")
+	cmd.Flags().StringVar(&opts.Context, "context", "", "Kubeconfig context of the management cluster")
+
+	return cmd
+}
+
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,72 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app_test
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/electrocucaracha/nephioadm/cmd/nephioadm/app"
+	internal "github.com/electrocucaracha/nephioadm/internal/app"
+	"github.com/electrocucaracha/nephioadm/internal/k8s"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+)
+
+func (m *mock) ListClusters(ctx context.Context, opts *k8s.ClusterOptions) ([]internal.WorkloadCluster, error) {
+	m.ClusterOpts = opts
+
+	return []internal.WorkloadCluster{
+		{
+			Name:   "regional",
+			Region: "us-west1",
+			Repo:   "http://gitea:3000/nephio-test/regional",
+			Labels: map[string]string{"nephio.org/site-type": "edge"},
+		},
+	}, nil
+}
+
+var _ = Describe("Clusters Command", func() {
+	var provider mock
+	var cmd *cobra.Command
+	var out *bytes.Buffer
+
+	BeforeEach(func() {
+		provider = mock{}
+		out = new(bytes.Buffer)
+		cmd = app.NewClustersCommand(&provider)
+		cmd.SetOut(out)
+	})
+
+	DescribeTable("clusters execution process", func(shouldSucceed bool, args ...string) {
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+
+		if shouldSucceed {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.String()).To(ContainSubstring("regional"))
+			Expect(out.String()).To(ContainSubstring("nephio.org/site-type=edge"))
+		} else {
+			Expect(err).To(HaveOccurred())
+		}
+	},
+		Entry("when the default options are provided", true),
+		Entry("when all options are defined", true, "--kubeconfig", "/tmp/kubeconfig", "--context", "kind-nephio"),
+		Entry("when invalid option is provided", false, "--invalid"),
+	)
+})
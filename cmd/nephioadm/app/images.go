@@ -0,0 +1,97 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	internal "github.com/electrocucaracha/nephioadm/internal/app"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// Output formats of the listing commands.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+func NewImagesCommand(provider internal.Provider) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "images",
+		Short: "Run this command in order to manage the images required by the Nephio components",
+	}
+
+	cmd.AddCommand(newImagesListCommand(provider))
+
+	return cmd
+}
+
+func newImagesListCommand(provider internal.Provider) *cobra.Command {
+	var (
+		globalOpts GlobalOptions
+		phase      string
+		output     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Fetch and render the Nephio packages and list the container and kpt function images they use",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != outputTable && output != outputJSON {
+				return errors.Errorf("unknown %q output format", output)
+			}
+
+			runnerOpts, err := globalOpts.runnerOptions()
+			if err != nil {
+				return err
+			}
+
+			images, err := provider.ListImages(cmd.Context(), &internal.ImagesOptions{Runner: *runnerOpts, Phase: phase})
+			if err != nil {
+				return errors.Wrap(err, "failed to list the nephio images")
+			}
+
+			if output == outputJSON {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+
+				return encoder.Encode(images)
+			}
+
+			writer := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 3, ' ', 0)
+			fmt.Fprintln(writer, "IMAGE\tTYPE\tCOMPONENTS")
+
+			for _, image := range images {
+				fmt.Fprintf(writer, "%s\t%s\t%s\n", image.Name, image.Type, strings.Join(image.Components, ","))
+			}
+
+			return writer.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&phase, "phase", "",
+		"Phase whose component images are listed ("+internal.PhaseInit+" or "+internal.PhaseJoin+"), all by default")
+	cmd.Flags().StringVarP(&output, "output", "o", outputTable, "Output format ("+outputTable+" or "+outputJSON+")")
+
+	cmd = GetCommandFlags(cmd, &globalOpts)
+
+	return cmd
+}
@@ -0,0 +1,80 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app_test
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/electrocucaracha/nephioadm/cmd/nephioadm/app"
+	internal "github.com/electrocucaracha/nephioadm/internal/app"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+)
+
+func (m *mock) ListImages(ctx context.Context, opts *internal.ImagesOptions) ([]internal.ImageInfo, error) {
+	m.ImagesOpts = opts
+
+	return []internal.ImageInfo{{
+		Name:       "gcr.io/kpt-fn/search-replace:v0.2",
+		Type:       internal.ImageTypeFunction,
+		Components: []string{internal.ComponentConfigSync},
+	}}, nil
+}
+
+var _ = Describe("Images Command", func() {
+	var provider mock
+	var cmd *cobra.Command
+	var out *bytes.Buffer
+
+	BeforeEach(func() {
+		provider = mock{}
+		out = new(bytes.Buffer)
+		cmd = app.NewImagesCommand(&provider)
+		cmd.SetOut(out)
+	})
+
+	DescribeTable("images execution process", func(expected string, args ...string) {
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+
+		if len(expected) != 0 {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.String()).To(ContainSubstring(expected))
+		} else {
+			Expect(err).To(HaveOccurred())
+		}
+	},
+		Entry("when the default options are provided", "gcr.io/kpt-fn/search-replace:v0.2   function   configsync",
+			"list"),
+		Entry("when the JSON output is requested", `"name": "gcr.io/kpt-fn/search-replace:v0.2"`,
+			"list", "-o", "json", "--phase", internal.PhaseJoin),
+		Entry("when an unknown output is requested", "", "list", "-o", "yaml"),
+		Entry("when invalid option is provided", "", "list", "--invalid"),
+	)
+
+	It("should pass the package options", func() {
+		cmd.SetArgs([]string{"list", "--phase", internal.PhaseJoin, "--nephio-repo", "./nephio-packages",
+			"--image-registry-mirror", "registry.local:5000"})
+
+		Expect(cmd.Execute()).To(Succeed())
+		Expect(provider.ImagesOpts.Phase).To(Equal(internal.PhaseJoin))
+		Expect(provider.ImagesOpts.Runner.NephioRepoURI).To(Equal("./nephio-packages"))
+		Expect(provider.ImagesOpts.Runner.ImageMirror.Registry).To(Equal("registry.local:5000"))
+	})
+})
@@ -31,26 +31,29 @@ func NewInitCommand(provider internal.Provider) *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			backendBaseUrl, _ := cmd.Flags().GetString("backend-base-url")
 			webUIClusterType, _ := cmd.Flags().GetString("webui-cluster-type")
+			locked, _ := cmd.Flags().GetBool("locked")
+			resume, _ := cmd.Flags().GetBool("resume")
 
-			runnerOpts := &internal.NephioRunnerOptions{
-				BasePath:         globalOpts.basePath,
-				NephioRepoURI:    globalOpts.nephioRepoURI,
-				GitServiceURI:    globalOpts.gitServiceURI,
-				BackendBaseUrl:   backendBaseUrl,
-				WebUIClusterType: webUIClusterType,
-				Debug:            globalOpts.debug,
+			runnerOpts, err := globalOpts.runnerOptions()
+			if err != nil {
+				return err
 			}
 
-			if err := provider.Init(runnerOpts); err != nil {
-				return errors.Wrap(err, "failed to init nephio cluster plane")
-			}
+			runnerOpts.BackendBaseUrl = backendBaseUrl
+			runnerOpts.WebUIClusterType = webUIClusterType
+			runnerOpts.Locked = locked
+			runnerOpts.Resume = resume
 
-			return nil
+			return withTracing(cmd, &globalOpts, runnerOpts, func() error {
+				return errors.Wrap(provider.Init(cmd.Context(), runnerOpts), "failed to init nephio cluster plane")
+			})
 		},
 	}
 
 	cmd.Flags().String("backend-base-url", "http://localhost:7007", "Nephio WebUI URL")
 	cmd.Flags().String("webui-cluster-type", "NodePort", "Nephio WebUI Cluster Type")
+	cmd.Flags().Bool("locked", false, "Refuse to install the packages which differ from the lock file")
+	cmd.Flags().Bool("resume", false, "Skip the package steps completed by an interrupted run, verifying them instead")
 
 	cmd = GetCommandFlags(cmd, &globalOpts)
 
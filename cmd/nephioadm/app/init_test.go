@@ -17,33 +17,86 @@ limitations under the License.
 package app_test
 
 import (
+	"context"
+	"time"
+
 	"github.com/electrocucaracha/nephioadm/cmd/nephioadm/app"
 	internal "github.com/electrocucaracha/nephioadm/internal/app"
+	"github.com/electrocucaracha/nephioadm/internal/k8s"
+	"github.com/electrocucaracha/nephioadm/internal/kpt"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type mock struct {
-	Opts *internal.NephioRunnerOptions
+	Opts             *internal.NephioRunnerOptions
+	ClusterOpts      *k8s.ClusterOptions
+	VerifyOpts       *internal.VerifyOptions
+	BundleOpts       *internal.SupportBundleOptions
+	FleetOpts        *internal.FleetOptions
+	CacheOpts        *internal.CacheOptions
+	BundleCreateOpts *internal.BundleOptions
+	ImagesOpts       *internal.ImagesOptions
 }
 
-func (m *mock) Init(opts *internal.NephioRunnerOptions) error {
+func (m *mock) Init(ctx context.Context, opts *internal.NephioRunnerOptions) error {
 	m.Opts = opts
 
 	return nil
 }
 
+func (m *mock) Reset(ctx context.Context, opts *internal.ResetOptions) error {
+	return nil
+}
+
+func (m *mock) Status(ctx context.Context, opts *internal.StatusOptions) ([]internal.ComponentStatus, error) {
+	return nil, nil
+}
+
+func (m *mock) InstallState(ctx context.Context, opts *k8s.ClusterOptions) (*internal.InstallState, error) {
+	return nil, nil
+}
+
 var _ = Describe("Init Command", func() {
 	var provider mock
 	var cmd *cobra.Command
 	testData := &internal.NephioRunnerOptions{
 		BasePath:         "/tmp",
 		NephioRepoURI:    "http://gitea:3000/playground/test.git",
+		NephioRepoRef:    "v1.0.1",
 		GitServiceURI:    "http://gitea:3000/nephio-test",
 		BackendBaseUrl:   "https://codespace-7007.preview.app.github.dev",
 		WebUIClusterType: "LoadBalancer",
-		Debug:            true,
+		SkipVerify:       true,
+		VerifyTimeout:    time.Minute,
+		ReconcileTimeout: 10 * time.Minute,
+		Parallelism:      2,
+		RetryPolicies: kpt.RetryPolicies{
+			kpt.OperationFetch: {MaxAttempts: 5, Backoff: 10 * time.Second, MaxBackoff: 30 * time.Second},
+			kpt.OperationFn:    {MaxAttempts: 2, Backoff: time.Second, MaxBackoff: 10 * time.Second},
+			kpt.OperationApply: {MaxAttempts: 3, Backoff: 2 * time.Minute, MaxBackoff: 2 * time.Minute},
+		},
+		CacheDir: "/var/cache/nephioadm",
+		Bundle:   "/tmp/nephio-bundle.tar.gz",
+		ImageMirror: k8s.ImageMirror{
+			Registry: "registry.local:5000",
+			Rules: []k8s.ImageRule{
+				{From: "gcr.io/kpt-fn", To: "registry.local:5000/kpt"},
+				{From: "quay.io", To: "registry.local:5000/quay"},
+			},
+		},
+		LockFile:   "/tmp/nephioadm.lock",
+		Locked:     true,
+		Resume:     true,
+		ReportFile: "/tmp/report.json",
+		JUnitFile:  "/tmp/junit.xml",
+		PatchesDir: "/tmp/patches",
+		Debug:      true,
+		WriteLogs:  true,
+
+		TracerProvider: trace.NewNoopTracerProvider(),
 	}
 
 	BeforeEach(func() {
@@ -68,10 +121,29 @@ var _ = Describe("Init Command", func() {
 		Entry("when all options are defined", true,
 			"--base-path", testData.BasePath,
 			"--nephio-repo", testData.NephioRepoURI,
+			"--nephio-repo-ref", testData.NephioRepoRef,
 			"--git-service", testData.GitServiceURI,
+			"--patches-dir", testData.PatchesDir,
+			"--reconcile-timeout", testData.ReconcileTimeout.String(),
+			"--parallelism", "2",
+			"--retry-attempts", "fetch=5",
+			"--retry-backoff", "fetch=10s,apply=2m",
+			"--cache-dir", testData.CacheDir,
+			"--bundle", testData.Bundle,
+			"--image-registry-mirror", testData.ImageMirror.Registry,
+			"--image-rewrite", "quay.io=registry.local:5000/quay,gcr.io/kpt-fn=registry.local:5000/kpt",
+			"--lock-file", testData.LockFile,
+			"--locked",
+			"--resume",
+			"--report", testData.ReportFile,
+			"--junit", testData.JUnitFile,
+			"--skip-verify",
+			"--verify-timeout", "1m",
 			"--backend-base-url", testData.BackendBaseUrl,
 			"--webui-cluster-type", testData.WebUIClusterType,
 			"--debug"),
 		Entry("when invalid option is provided", false, "--invalid"),
+		Entry("when an unknown retry operation is provided", false, "--retry-attempts", "unknown=2"),
+		Entry("when an invalid retry backoff is provided", false, "--retry-backoff", "fetch=invalid"),
 	)
 })
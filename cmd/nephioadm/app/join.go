@@ -23,28 +23,48 @@ import (
 )
 
 func NewJoinCommand(provider internal.Provider) *cobra.Command {
-	var opts GlobalOptions
+	var globalOpts GlobalOptions
 
 	cmd := &cobra.Command{
 		Use:   "join",
 		Short: "Run this command in order to join a Cluster to the existing Nephio control plane",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			opts := &internal.NephioRunnerOptions{
-				BasePath:      opts.basePath,
-				NephioRepoURI: opts.nephioRepoURI,
-				GitServiceURI: opts.gitServiceURI,
-				Debug:         opts.debug,
-			}
+			mgmtKubeconfig, _ := cmd.Flags().GetString("mgmt-kubeconfig")
+			mgmtContext, _ := cmd.Flags().GetString("mgmt-context")
+			clusterName, _ := cmd.Flags().GetString("cluster-name")
+			clusterRegion, _ := cmd.Flags().GetString("cluster-region")
+			clusterLabels, _ := cmd.Flags().GetStringToString("cluster-labels")
+			locked, _ := cmd.Flags().GetBool("locked")
+			resume, _ := cmd.Flags().GetBool("resume")
 
-			if err := provider.Join(opts); err != nil {
-				return errors.Wrap(err, "failed to join to the nephio cluster plane")
+			opts, err := globalOpts.runnerOptions()
+			if err != nil {
+				return err
 			}
 
-			return nil
+			opts.MgmtKubeconfig = mgmtKubeconfig
+			opts.MgmtContext = mgmtContext
+			opts.ClusterName = clusterName
+			opts.ClusterRegion = clusterRegion
+			opts.ClusterLabels = clusterLabels
+			opts.Locked = locked
+			opts.Resume = resume
+
+			return withTracing(cmd, &globalOpts, opts, func() error {
+				return errors.Wrap(provider.Join(cmd.Context(), opts), "failed to join to the nephio cluster plane")
+			})
 		},
 	}
 
-	cmd = GetCommandFlags(cmd, &opts)
+	cmd.Flags().String("mgmt-kubeconfig", "", "Kubeconfig file of the management cluster where this cluster is registered")
+	cmd.Flags().String("mgmt-context", "", "Kubeconfig context of the management cluster where this cluster is registered")
+	cmd.Flags().String("cluster-name", "", "Name used to register this cluster in the management cluster")
+	cmd.Flags().String("cluster-region", "", "Region of this cluster")
+	cmd.Flags().StringToString("cluster-labels", map[string]string{}, "Labels of this cluster")
+	cmd.Flags().Bool("locked", false, "Refuse to install the packages which differ from the lock file")
+	cmd.Flags().Bool("resume", false, "Skip the package steps completed by an interrupted run, verifying them instead")
+
+	cmd = GetCommandFlags(cmd, &globalOpts)
 
 	return cmd
 }
@@ -17,14 +17,19 @@ limitations under the License.
 package app_test
 
 import (
+	"context"
+	"time"
+
 	"github.com/electrocucaracha/nephioadm/cmd/nephioadm/app"
 	internal "github.com/electrocucaracha/nephioadm/internal/app"
+	"github.com/electrocucaracha/nephioadm/internal/kpt"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/trace"
 )
 
-func (m *mock) Join(opts *internal.NephioRunnerOptions) error {
+func (m *mock) Join(ctx context.Context, opts *internal.NephioRunnerOptions) error {
 	m.Opts = opts
 
 	return nil
@@ -34,10 +39,33 @@ var _ = Describe("Join Command", func() {
 	var provider mock
 	var cmd *cobra.Command
 	testData := &internal.NephioRunnerOptions{
-		BasePath:      "/tmp",
-		NephioRepoURI: "http://gitea:3000/playground/test.git",
-		GitServiceURI: "http://gitea:3000/nephio-test",
-		Debug:         true,
+		BasePath:         "/tmp",
+		NephioRepoURI:    "http://gitea:3000/playground/test.git",
+		NephioRepoRef:    "v1.0.1",
+		GitServiceURI:    "http://gitea:3000/nephio-test",
+		SkipVerify:       true,
+		VerifyTimeout:    time.Minute,
+		ReconcileTimeout: 10 * time.Minute,
+		Parallelism:      2,
+		RetryPolicies: kpt.RetryPolicies{
+			kpt.OperationFetch: {MaxAttempts: 5, Backoff: 10 * time.Second, MaxBackoff: 30 * time.Second},
+			kpt.OperationFn:    {MaxAttempts: 2, Backoff: time.Second, MaxBackoff: 10 * time.Second},
+			kpt.OperationApply: {MaxAttempts: 3, Backoff: 2 * time.Minute, MaxBackoff: 2 * time.Minute},
+		},
+		LockFile:       "/tmp/nephioadm.lock",
+		Locked:         true,
+		Resume:         true,
+		ReportFile:     "/tmp/report.json",
+		JUnitFile:      "/tmp/junit.xml",
+		PatchesDir:     "/tmp/patches",
+		Debug:          true,
+		WriteLogs:      true,
+		TracerProvider: trace.NewNoopTracerProvider(),
+		MgmtKubeconfig: "/tmp/kubeconfig",
+		MgmtContext:    "kind-nephio",
+		ClusterName:    "regional",
+		ClusterRegion:  "us-west1",
+		ClusterLabels:  map[string]string{"nephio.org/site-type": "edge"},
 	}
 
 	BeforeEach(func() {
@@ -62,8 +90,28 @@ var _ = Describe("Join Command", func() {
 		Entry("when all options are defined", true,
 			"--base-path", testData.BasePath,
 			"--nephio-repo", testData.NephioRepoURI,
+			"--nephio-repo-ref", testData.NephioRepoRef,
 			"--git-service", testData.GitServiceURI,
+			"--patches-dir", testData.PatchesDir,
+			"--reconcile-timeout", testData.ReconcileTimeout.String(),
+			"--parallelism", "2",
+			"--retry-attempts", "fetch=5",
+			"--retry-backoff", "fetch=10s,apply=2m",
+			"--lock-file", testData.LockFile,
+			"--locked",
+			"--resume",
+			"--report", testData.ReportFile,
+			"--junit", testData.JUnitFile,
+			"--skip-verify",
+			"--verify-timeout", "1m",
+			"--mgmt-kubeconfig", testData.MgmtKubeconfig,
+			"--mgmt-context", testData.MgmtContext,
+			"--cluster-name", testData.ClusterName,
+			"--cluster-region", testData.ClusterRegion,
+			"--cluster-labels", "nephio.org/site-type=edge",
 			"--debug"),
 		Entry("when invalid option is provided", false, "--invalid"),
+		Entry("when an unknown retry operation is provided", false, "--retry-attempts", "unknown=2"),
+		Entry("when an invalid retry backoff is provided", false, "--retry-backoff", "fetch=invalid"),
 	)
 })
@@ -17,32 +17,85 @@ limitations under the License.
 package app
 
 import (
+	"context"
 	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
 
 	internal "github.com/electrocucaracha/nephioadm/internal/app"
 	"github.com/electrocucaracha/nephioadm/internal/k8s"
 	"github.com/electrocucaracha/nephioadm/internal/kpt"
+	"github.com/electrocucaracha/nephioadm/internal/logging"
+	"github.com/electrocucaracha/nephioadm/internal/tracing"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
 type GlobalOptions struct {
-	basePath      string
-	nephioRepoURI string
-	gitServiceURI string
-	debug         bool
+	basePath         string
+	nephioRepoURI    string
+	nephioRepoRef    string
+	gitServiceURI    string
+	patchesDir       string
+	skipVerify       bool
+	verifyTimeout    time.Duration
+	reconcileTimeout time.Duration
+	parallelism      int
+	retryAttempts    map[string]int
+	retryBackoff     map[string]string
+	cacheDir         string
+	bundle           string
+	lockFile         string
+	imageMirror      string
+	imageRewrites    map[string]string
+	reportFile       string
+	junitFile        string
+	traceEndpoint    string
+	traceFile        string
+	debug            bool
 }
 
 func NewRootCommand() *cobra.Command {
+	var (
+		verbosity int
+		logFormat string
+	)
+
+	fSys := k8s.MakeFsOnDisk()
+	provider := internal.NewProvider(&kpt.CommandLine{FileSystem: fSys}, fSys, k8s.NewCluster)
+
 	cmd := &cobra.Command{
 		Use:   "nephioadm",
 		Short: "nephioadm: easily bootstrap Nephio cluster",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			logger, err := logging.NewLogger(os.Stderr, logFormat, verbosity)
+			if err != nil {
+				return err
+			}
+
+			provider.SetLogger(logger)
+
+			return nil
+		},
 	}
 
-	provider := internal.NewProvider(&kpt.CommandLine{}, k8s.ReadResourceFromFile,
-		k8s.WriteResourceToFile)
+	cmd.PersistentFlags().IntVarP(&verbosity, "verbosity", "v", logging.LevelInfo,
+		"Log verbosity level (1 reports the kpt commands, 2 their output)")
+	cmd.PersistentFlags().StringVar(&logFormat, "log-format", logging.FormatText,
+		"Log format ("+strings.Join(logging.Formats, " or ")+")")
 
 	cmd.AddCommand(NewInitCommand(provider))
 	cmd.AddCommand(NewJoinCommand(provider))
+	cmd.AddCommand(NewApplyCommand(provider))
+	cmd.AddCommand(NewClustersCommand(provider))
+	cmd.AddCommand(NewVerifyCommand(provider))
+	cmd.AddCommand(NewSupportBundleCommand(provider))
+	cmd.AddCommand(NewCacheCommand(provider))
+	cmd.AddCommand(NewBundleCommand(provider))
+	cmd.AddCommand(NewImagesCommand(provider))
 
 	return cmd
 }
@@ -50,7 +103,11 @@ func NewRootCommand() *cobra.Command {
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	if err := NewRootCommand().Execute(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := NewRootCommand().ExecuteContext(ctx); err != nil {
+		stop()
 		os.Exit(1)
 	}
 }
@@ -60,11 +117,156 @@ func GetCommandFlags(cmd *cobra.Command, opts *GlobalOptions) *cobra.Command {
 
 	flags.StringVar(&opts.basePath, "base-path", internal.DefaultBasePath,
 		"The local directory to write the Nephio's packages to")
-	flags.StringVar(&opts.nephioRepoURI, "nephio-repo", "https://github.com/nephio-project/nephio-packages.git",
-		"URI of a git repository containing Nephio's packages (System, WebUI, ConfigSync) as subdirectories")
-	flags.StringVar(&opts.gitServiceURI, "git-service", "https://github.com/nephio-test/",
+	flags.StringVar(&opts.nephioRepoURI, "nephio-repo", internal.DefaultNephioRepoURI,
+		"URI of a git repository, local directory or tarball (file://) containing Nephio's packages "+
+			"(System, WebUI, ConfigSync) as subdirectories")
+	flags.StringVar(&opts.nephioRepoRef, "nephio-repo-ref", "",
+		"Git reference of the Nephio repository the packages are fetched from (default branch)")
+	flags.StringVar(&opts.gitServiceURI, "git-service", internal.DefaultGitServiceURI,
 		"URI of a Git Service")
+	flags.StringVar(&opts.patchesDir, "patches-dir", "",
+		"Directory containing strategic-merge and JSON6902 patches for each package (system, webui, configsync) "+
+			"as subdirectories")
+	flags.BoolVar(&opts.skipVerify, "skip-verify", false, "Skip the verification of the installed components")
+	flags.DurationVar(&opts.verifyTimeout, "verify-timeout", internal.DefaultVerifyTimeout,
+		"Time to wait for the installed components to be ready")
+	flags.DurationVar(&opts.reconcileTimeout, "reconcile-timeout", internal.DefaultReconcileTimeout,
+		"Time to wait for the resources of each package to be reconciled")
+	flags.IntVar(&opts.parallelism, "parallelism", internal.DefaultParallelism,
+		"Maximum number of package operations run concurrently")
+	flags.StringToIntVar(&opts.retryAttempts, "retry-attempts", map[string]int{},
+		"Maximum attempts of the kpt operations with transient failures ("+strings.Join(kpt.Operations, ", ")+
+			"), e.g. fetch=5,apply=3")
+	flags.StringToStringVar(&opts.retryBackoff, "retry-backoff", map[string]string{},
+		"Initial backoff between the attempts of the kpt operations, which is doubled on every retry, e.g. fetch=5s")
+	flags.StringVar(&opts.cacheDir, "cache-dir", "",
+		"Directory where the fetched packages are cached, so they're copied instead of fetched again")
+	flags.StringVar(&opts.imageMirror, "image-registry-mirror", "",
+		"Registry the container and kpt function images are pulled from, e.g. registry.local:5000")
+	flags.StringToStringVar(&opts.imageRewrites, "image-rewrite", map[string]string{},
+		"Image reference prefixes replaced before the registry mirror, e.g. gcr.io/kpt-fn=registry.local:5000/kpt-fn")
+	flags.StringVar(&opts.bundle, "bundle", "",
+		"Archive created by the bundle create command, the packages are installed from it instead of --nephio-repo")
+	flags.StringVar(&opts.lockFile, "lock-file", "",
+		"File where the resolved revisions and checksums of the fetched packages are written to, "+
+			"nephioadm.lock of --base-path by default")
+	flags.StringVar(&opts.reportFile, "report", "", "JSON file where the results of the phase steps are written to")
+	flags.StringVar(&opts.junitFile, "junit", "", "JUnit XML file where the results of the phase steps are written to")
+	flags.StringVar(&opts.traceEndpoint, "trace-endpoint", "",
+		"OTLP/HTTP endpoint where the traces of the phase and kpt commands are exported to")
+	flags.StringVar(&opts.traceFile, "trace-file", "", "JSON file where the traces are written to")
 	flags.BoolVar(&opts.debug, "debug", false, "Enable debug mode")
 
 	return cmd
 }
+
+// withTracing runs the function provided exporting the spans of the runner options to the
+// destinations of the global options, the pending spans are flushed once it finishes.
+func withTracing(cmd *cobra.Command, opts *GlobalOptions, runnerOpts *internal.NephioRunnerOptions,
+	run func() error,
+) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	provider, shutdown, err := tracing.Setup(ctx, &tracing.Options{Endpoint: opts.traceEndpoint, File: opts.traceFile})
+	if err != nil {
+		return err
+	}
+
+	runnerOpts.TracerProvider = provider
+
+	runErr := run()
+
+	if err := shutdown(ctx); err != nil && runErr == nil {
+		return err
+	}
+
+	return runErr
+}
+
+// retryPolicies overrides the default kpt retry policies with the attempts and backoffs provided.
+func (o *GlobalOptions) retryPolicies() (kpt.RetryPolicies, error) {
+	policies := kpt.DefaultRetryPolicies()
+
+	for operation, attempts := range o.retryAttempts {
+		policy, ok := policies[operation]
+		if !ok {
+			return nil, errors.Errorf("unknown %q retry operation", operation)
+		}
+
+		policy.MaxAttempts = attempts
+		policies[operation] = policy
+	}
+
+	for operation, value := range o.retryBackoff {
+		policy, ok := policies[operation]
+		if !ok {
+			return nil, errors.Errorf("unknown %q retry operation", operation)
+		}
+
+		backoff, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s retry backoff", operation)
+		}
+
+		policy.Backoff = backoff
+		if policy.MaxBackoff < backoff {
+			policy.MaxBackoff = backoff
+		}
+
+		policies[operation] = policy
+	}
+
+	return policies, nil
+}
+
+// runnerOptions returns the runner options shared by the phase commands.
+func (o *GlobalOptions) runnerOptions() (*internal.NephioRunnerOptions, error) {
+	retryPolicies, err := o.retryPolicies()
+	if err != nil {
+		return nil, err
+	}
+
+	return &internal.NephioRunnerOptions{
+		BasePath:         o.basePath,
+		NephioRepoURI:    o.nephioRepoURI,
+		NephioRepoRef:    o.nephioRepoRef,
+		GitServiceURI:    o.gitServiceURI,
+		PatchesDir:       o.patchesDir,
+		SkipVerify:       o.skipVerify,
+		VerifyTimeout:    o.verifyTimeout,
+		ReconcileTimeout: o.reconcileTimeout,
+		Parallelism:      o.parallelism,
+		RetryPolicies:    retryPolicies,
+		CacheDir:         o.cacheDir,
+		Bundle:           o.bundle,
+		LockFile:         o.lockFile,
+		ImageMirror:      o.imageMirrorRules(),
+		ReportFile:       o.reportFile,
+		JUnitFile:        o.junitFile,
+		Debug:            o.debug,
+		WriteLogs:        true,
+	}, nil
+}
+
+// imageMirrorRules returns the image mirror of the registry and rewrite rules provided, the most
+// specific rules are applied first.
+func (o *GlobalOptions) imageMirrorRules() k8s.ImageMirror {
+	mirror := k8s.ImageMirror{Registry: o.imageMirror}
+
+	for from, to := range o.imageRewrites {
+		mirror.Rules = append(mirror.Rules, k8s.ImageRule{From: from, To: to})
+	}
+
+	sort.Slice(mirror.Rules, func(i, j int) bool {
+		if len(mirror.Rules[i].From) != len(mirror.Rules[j].From) {
+			return len(mirror.Rules[i].From) > len(mirror.Rules[j].From)
+		}
+
+		return mirror.Rules[i].From < mirror.Rules[j].From
+	})
+
+	return mirror
+}
@@ -20,7 +20,7 @@ import (
 )
 
 var _ = Describe("Root Command", func() {
-	const numberImplementedCommands = 2
+	const numberImplementedCommands = 9
 
 	Describe("Initialization process", func() {
 		Context("when default options are provided", func() {
@@ -28,5 +28,14 @@ var _ = Describe("Root Command", func() {
 				Expect(app.NewRootCommand().Commands()).To(HaveLen(numberImplementedCommands))
 			})
 		})
+
+		Context("when an unsupported log format is provided", func() {
+			It("should fail before running the subcommand", func() {
+				cmd := app.NewRootCommand()
+				cmd.SetArgs([]string{"clusters", "--log-format", "xml"})
+
+				Expect(cmd.Execute()).To(MatchError(ContainSubstring("unsupported \"xml\" log format")))
+			})
+		})
 	})
 })
@@ -0,0 +1,57 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"time"
+
+	internal "github.com/electrocucaracha/nephioadm/internal/app"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func NewSupportBundleCommand(provider internal.Provider) *cobra.Command {
+	var opts internal.SupportBundleOptions
+
+	cmd := &cobra.Command{
+		Use:   "support-bundle",
+		Short: "Run this command in order to collect the diagnostics of a Nephio installation into a tarball",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(opts.Output) == 0 {
+				opts.Output = "nephioadm-support-bundle-" + time.Now().Format("20060102150405") + ".tar.gz"
+			}
+
+			if err := provider.SupportBundle(cmd.Context(), &opts); err != nil {
+				return errors.Wrap(err, "failed to collect the support bundle")
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Support bundle written to %s\n", opts.Output)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Cluster.Kubeconfig, "kubeconfig", "", "Kubeconfig file of the cluster")
+	cmd.Flags().StringVar(&opts.Cluster.Context, "context", "", "Kubeconfig context of the cluster")
+	cmd.Flags().StringVar(&opts.BasePath, "base-path", internal.DefaultBasePath,
+		"The local directory where the Nephio's packages were written to")
+	cmd.Flags().StringVarP(&opts.Output, "output", "o", "",
+		"Tarball file where the diagnostics are written to (nephioadm-support-bundle-<timestamp>.tar.gz by default)")
+
+	return cmd
+}
@@ -0,0 +1,76 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app_test
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/electrocucaracha/nephioadm/cmd/nephioadm/app"
+	internal "github.com/electrocucaracha/nephioadm/internal/app"
+	"github.com/electrocucaracha/nephioadm/internal/k8s"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+)
+
+func (m *mock) SupportBundle(ctx context.Context, opts *internal.SupportBundleOptions) error {
+	m.BundleOpts = opts
+
+	return nil
+}
+
+var _ = Describe("Support Bundle Command", func() {
+	var provider mock
+	var cmd *cobra.Command
+	var out *bytes.Buffer
+	testData := &internal.SupportBundleOptions{
+		Cluster:  k8s.ClusterOptions{Kubeconfig: "/tmp/kubeconfig", Context: "kind-nephio"},
+		BasePath: "/tmp/nephio",
+		Output:   "/tmp/bundle.tar.gz",
+	}
+
+	BeforeEach(func() {
+		provider = mock{}
+		out = new(bytes.Buffer)
+		cmd = app.NewSupportBundleCommand(&provider)
+		cmd.SetOut(out)
+	})
+
+	DescribeTable("support bundle execution process", func(shouldSucceed bool, args ...string) {
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+
+		if shouldSucceed {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.String()).To(ContainSubstring(provider.BundleOpts.Output))
+			if len(args) > 0 {
+				Expect(testData).To(Equal(provider.BundleOpts))
+			}
+		} else {
+			Expect(err).To(HaveOccurred())
+		}
+	},
+		Entry("when the default options are provided", true),
+		Entry("when all options are defined", true,
+			"--kubeconfig", testData.Cluster.Kubeconfig,
+			"--context", testData.Cluster.Context,
+			"--base-path", testData.BasePath,
+			"--output", testData.Output),
+		Entry("when invalid option is provided", false, "--invalid"),
+	)
+})
@@ -0,0 +1,69 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	internal "github.com/electrocucaracha/nephioadm/internal/app"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func NewVerifyCommand(provider internal.Provider) *cobra.Command {
+	var opts internal.VerifyOptions
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Run this command in order to verify the Nephio components installed on a Cluster",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			results, err := provider.Verify(cmd.Context(), &opts)
+
+			writer := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 3, ' ', 0)
+			fmt.Fprintln(writer, "CHECK\tSTATUS\tDURATION\tMESSAGE")
+
+			for _, result := range results {
+				status := "Ready"
+				if !result.Ready {
+					status = "NotReady"
+				}
+
+				fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", result.Name, status, result.Duration, result.Message)
+			}
+
+			if flushErr := writer.Flush(); flushErr != nil {
+				return flushErr
+			}
+
+			if err != nil {
+				return errors.Wrap(err, "failed to verify the nephio components")
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Cluster.Kubeconfig, "kubeconfig", "", "Kubeconfig file of the cluster")
+	cmd.Flags().StringVar(&opts.Cluster.Context, "context", "", "Kubeconfig context of the cluster")
+	cmd.Flags().StringVar(&opts.Phase, "phase", internal.PhaseInit,
+		"Phase whose components are verified ("+internal.PhaseInit+" or "+internal.PhaseJoin+")")
+	cmd.Flags().DurationVar(&opts.Timeout, "timeout", internal.DefaultVerifyTimeout,
+		"Time to wait for the components to be ready")
+
+	return cmd
+}
@@ -0,0 +1,79 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/electrocucaracha/nephioadm/cmd/nephioadm/app"
+	internal "github.com/electrocucaracha/nephioadm/internal/app"
+	"github.com/electrocucaracha/nephioadm/internal/k8s"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+)
+
+func (m *mock) Verify(ctx context.Context, opts *internal.VerifyOptions) ([]internal.CheckResult, error) {
+	m.VerifyOpts = opts
+
+	return []internal.CheckResult{
+		{Name: "Nephio WebUI", Ready: true, Duration: time.Second},
+	}, nil
+}
+
+var _ = Describe("Verify Command", func() {
+	var provider mock
+	var cmd *cobra.Command
+	var out *bytes.Buffer
+	testData := &internal.VerifyOptions{
+		Cluster: k8s.ClusterOptions{Kubeconfig: "/tmp/kubeconfig", Context: "kind-regional"},
+		Phase:   internal.PhaseJoin,
+		Timeout: time.Minute,
+	}
+
+	BeforeEach(func() {
+		provider = mock{}
+		out = new(bytes.Buffer)
+		cmd = app.NewVerifyCommand(&provider)
+		cmd.SetOut(out)
+	})
+
+	DescribeTable("verify execution process", func(shouldSucceed bool, args ...string) {
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+
+		if shouldSucceed {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(out.String()).To(ContainSubstring("Nephio WebUI"))
+			if len(args) > 0 {
+				Expect(testData).To(Equal(provider.VerifyOpts))
+			}
+		} else {
+			Expect(err).To(HaveOccurred())
+		}
+	},
+		Entry("when the default options are provided", true),
+		Entry("when all options are defined", true,
+			"--kubeconfig", testData.Cluster.Kubeconfig,
+			"--context", testData.Cluster.Context,
+			"--phase", testData.Phase,
+			"--timeout", "1m"),
+		Entry("when invalid option is provided", false, "--invalid"),
+	)
+})
@@ -0,0 +1,124 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/electrocucaracha/nephioadm/internal/bundle"
+	"github.com/electrocucaracha/nephioadm/internal/k8s"
+	"github.com/electrocucaracha/nephioadm/internal/kpt"
+	"github.com/electrocucaracha/nephioadm/internal/source"
+	"github.com/pkg/errors"
+)
+
+type BundleOptions struct {
+	// Output is the gzip tarball the bundle is written to
+	Output        string
+	NephioRepoURI string
+	// Ref pins the packages to a git reference, the default branch is used when it isn't set
+	Ref           string
+	CacheDir      string
+	RetryPolicies kpt.RetryPolicies
+}
+
+// CreateBundle fetches the packages of all the components and archives them with their lock data,
+// the images they require and their checksums, so they can be installed without network access.
+// The images are collected from a rendered copy of the packages, as ListImages does, while the
+// packages are archived as they were fetched.
+func (p NephioProvider) CreateBundle(ctx context.Context, opts *BundleOptions) (*bundle.Manifest, error) {
+	workDir := filepath.Join(filepath.Dir(opts.Output), "."+filepath.Base(opts.Output)+".work")
+	if err := p.fSys.RemoveAll(workDir); err != nil {
+		return nil, errors.Wrapf(err, "failed to remove the stale %s work directory", workDir)
+	}
+	defer p.fSys.RemoveAll(workDir)
+
+	runner, err := p.newPackageRunner(ctx, &NephioRunnerOptions{
+		BasePath:      workDir,
+		NephioRepoURI: opts.NephioRepoURI,
+		NephioRepoRef: opts.Ref,
+		RetryPolicies: opts.RetryPolicies,
+		CacheDir:      opts.CacheDir,
+	}, p.log)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &bundle.Manifest{
+		Repository: opts.NephioRepoURI,
+		Ref:        opts.Ref,
+		Created:    time.Now().UTC().Truncate(time.Second),
+	}
+	packages := map[string]string{}
+	idx := imageIndex{}
+
+	for _, component := range allComponents() {
+		if err := runner.getPackage(component); err != nil {
+			return nil, err
+		}
+
+		path := runner.packagePath(component)
+		fetched := filepath.Join(workDir, bundle.PackagesDir, componentPackages[component])
+
+		if err := k8s.CopyDir(p.fSys, path, fetched); err != nil {
+			return nil, errors.Wrapf(err, "failed to copy the %s package", componentPackages[component])
+		}
+
+		packages[componentPackages[component]] = fetched
+		manifest.Packages = append(manifest.Packages, bundle.Package{
+			Component: component,
+			Path:      componentPackages[component],
+			Commit:    kpt.LockedCommit(p.fSys, path),
+		})
+
+		if err := runner.renderPackage(component); err != nil {
+			return nil, err
+		}
+
+		if err := runner.collectImages(idx, component); err != nil {
+			return nil, err
+		}
+	}
+
+	manifest.Images = bundle.Images{Functions: idx.names(ImageTypeFunction), Containers: idx.names(ImageTypeContainer)}
+
+	if err := bundle.Write(p.fSys, opts.Output, manifest, packages); err != nil {
+		return nil, err
+	}
+
+	p.log.Info("Bundle created", "file", opts.Output, "repo", manifest.Repository, "ref", manifest.Ref)
+
+	return manifest, nil
+}
+
+// bundleUpstream returns the git revision the bundled component package was fetched from, it's
+// nil when the package wasn't fetched from a git repository.
+func (r *NephioRunner) bundleUpstream(component string) *source.Upstream {
+	for _, pkg := range r.bundleManifest.Packages {
+		if pkg.Component == component && len(pkg.Commit) != 0 {
+			return &source.Upstream{
+				RepoURI:   r.bundleManifest.Repository,
+				Directory: pkg.Path,
+				Ref:       r.bundleManifest.Ref,
+				Commit:    pkg.Commit,
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package app_test
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/electrocucaracha/nephioadm/internal/app"
+	"github.com/electrocucaracha/nephioadm/internal/lock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+var _ = Describe("Bundle", func() {
+	var provider *app.NephioProvider
+	var client *mockClient
+	var fSys filesys.FileSystem
+
+	BeforeEach(func() {
+		fSys = filesys.MakeFsInMemory()
+		for _, pkg := range []string{"nephio-system", "nephio-webui", "nephio-configsync"} {
+			Expect(fSys.WriteFile("/src/nephio-packages/"+pkg+"/Kptfile", []byte(`apiVersion: kpt.dev/v1
+kind: Kptfile
+metadata:
+  name: `+pkg+`
+pipeline:
+  mutators:
+  - image: gcr.io/kpt-fn/set-namespace:v0.4.1
+`))).To(Succeed())
+		}
+		Expect(fSys.WriteFile("/src/nephio-packages/nephio-system/deployment.yaml", []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: porch-server
+spec:
+  template:
+    spec:
+      containers:
+      - name: porch-server
+        image: docker.io/nephio/porch-server:v1.0.0
+`))).To(Succeed())
+
+		client = NewMockClient()
+		provider = app.NewProvider(client, fSys, NewMockCluster().newCluster)
+	})
+
+	It("should install the packages from the bundle alone", func() {
+		manifest, err := provider.CreateBundle(context.Background(), &app.BundleOptions{
+			Output:        "/tmp/nephio-bundle.tar.gz",
+			NephioRepoURI: "/src/nephio-packages",
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(manifest.Packages).To(HaveLen(3))
+		Expect(manifest.Images.Functions).To(ConsistOf(
+			"gcr.io/kpt-fn/search-replace:v0.2", "gcr.io/kpt-fn/set-namespace:v0.4.1"))
+		Expect(manifest.Images.Containers).To(ConsistOf("docker.io/nephio/porch-server:v1.0.0"))
+		Expect(client.FnRenderCallerCount).To(Equal(3), "the images are collected from the rendered packages")
+		Expect(fSys.Exists("/tmp/.nephio-bundle.tar.gz.work")).To(BeFalse())
+
+		Expect(fSys.RemoveAll("/src")).To(Succeed())
+		Expect(provider.Join(context.Background(), &app.NephioRunnerOptions{
+			BasePath:      "/opt/nephio/edge",
+			NephioRepoURI: app.DefaultNephioRepoURI,
+			Bundle:        "/tmp/nephio-bundle.tar.gz",
+			SkipVerify:    true,
+		})).To(Succeed())
+
+		Expect(client.PkgGetCallerCount).To(BeZero())
+		Expect(fSys.Exists("/opt/nephio/edge/configsync/Kptfile")).To(BeTrue())
+	})
+
+	It("should record the upstream of the bundled git packages", func() {
+		client.OnPkgGet = func(path, source string) {
+			Expect(fSys.WriteFile(filepath.Join(path, "Kptfile"), []byte("kind: Kptfile\nupstreamLock:\n  git:\n"+
+				"    commit: "+lockedCommit+"\n"))).To(Succeed())
+		}
+
+		_, err := provider.CreateBundle(context.Background(), &app.BundleOptions{
+			Output:        "/tmp/nephio-bundle.tar.gz",
+			NephioRepoURI: app.DefaultNephioRepoURI,
+			Ref:           "v1.0.1",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(provider.Join(context.Background(), &app.NephioRunnerOptions{
+			BasePath:      "/opt/nephio/edge",
+			NephioRepoURI: app.DefaultNephioRepoURI,
+			NephioRepoRef: "v1.0.1",
+			Bundle:        "/tmp/nephio-bundle.tar.gz",
+			SkipVerify:    true,
+		})).To(Succeed())
+
+		Expect(fSys.ReadFile("/opt/nephio/edge/configsync/Kptfile")).To(BeEquivalentTo(`kind: Kptfile
+metadata:
+  name: configsync
+upstream:
+  type: git
+  git:
+    repo: ` + app.DefaultNephioRepoURI + `
+    directory: /nephio-configsync
+    ref: v1.0.1
+upstreamLock:
+  type: git
+  git:
+    repo: ` + app.DefaultNephioRepoURI + `
+    directory: /nephio-configsync
+    ref: v1.0.1
+    commit: ` + lockedCommit + "\n"))
+
+		lockFile, err := lock.Read(fSys, "/opt/nephio/edge/nephioadm.lock")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lockFile.Packages).To(HaveLen(1))
+		Expect(lockFile.Packages[0].Commit).To(Equal(lockedCommit))
+	})
+
+	It("should fail when the bundle is tampered", func() {
+		_, err := provider.CreateBundle(context.Background(), &app.BundleOptions{
+			Output:        "/tmp/nephio-bundle.tar.gz",
+			NephioRepoURI: "/src/nephio-packages",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fSys.WriteFile("/tmp/nephio-bundle.tar.gz", []byte("invalid"))).To(Succeed())
+
+		Expect(provider.Join(context.Background(), &app.NephioRunnerOptions{
+			BasePath: "/opt/nephio/edge",
+			Bundle:   "/tmp/nephio-bundle.tar.gz",
+		})).To(MatchError(ContainSubstring("failed to decompress the /tmp/nephio-bundle.tar.gz bundle")))
+		Expect(client.LiveApplyCallerCount).To(BeZero())
+	})
+})
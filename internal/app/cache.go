@@ -0,0 +1,44 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/electrocucaracha/nephioadm/internal/cache"
+)
+
+type CacheOptions struct {
+	Dir string
+	// UnusedFor prunes the packages which haven't been used for longer, all of them are pruned when it isn't set
+	UnusedFor time.Duration
+}
+
+// ListCache returns the packages stored in the cache directory.
+func (p NephioProvider) ListCache(ctx context.Context, opts *CacheOptions) ([]cache.Entry, error) {
+	return cache.New(p.fSys, opts.Dir, p.resolve).List()
+}
+
+// PruneCache removes the packages from the cache directory and returns them.
+func (p NephioProvider) PruneCache(ctx context.Context, opts *CacheOptions) ([]cache.Entry, error) {
+	pruned, err := cache.New(p.fSys, opts.Dir, p.resolve).Prune(opts.UnusedFor)
+	for _, entry := range pruned {
+		p.log.Info("Pruned cached package", "repo", entry.RepoURI, "package", entry.Path, "commit", entry.Commit)
+	}
+
+	return pruned, err
+}
@@ -0,0 +1,75 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package app_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/electrocucaracha/nephioadm/internal/app"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+var _ = Describe("Package cache", func() {
+	var provider *app.NephioProvider
+	var client *mockClient
+	var fSys filesys.FileSystem
+	cacheOpts := &app.CacheOptions{Dir: "/var/cache/nephioadm"}
+
+	BeforeEach(func() {
+		fSys = filesys.MakeFsInMemory()
+		client = NewMockClient()
+		client.OnCommand = func(command string) {
+			if path, ok := strings.CutPrefix(command, "pkg get "); ok {
+				Expect(fSys.WriteFile(filepath.Join(path, "Kptfile"),
+					[]byte("kind: Kptfile\nmetadata:\n  name: "+filepath.Base(path)+"\n"))).To(Succeed())
+			}
+		}
+		provider = app.NewProvider(client, fSys, NewMockCluster().newCluster)
+		provider.SetResolver(func(ctx context.Context, repoURI, ref string) (string, error) {
+			return "0123456789abcdef0123456789abcdef01234567", nil
+		})
+	})
+
+	It("should fetch the packages once for all the clusters", func() {
+		for _, basePath := range []string{"/opt/nephio/edge-1", "/opt/nephio/edge-2"} {
+			Expect(provider.Join(context.Background(), &app.NephioRunnerOptions{
+				BasePath:      basePath,
+				NephioRepoURI: app.DefaultNephioRepoURI,
+				CacheDir:      cacheOpts.Dir,
+				SkipVerify:    true,
+			})).To(Succeed())
+			Expect(fSys.ReadFile(filepath.Join(basePath, "configsync", "Kptfile"))).To(BeEquivalentTo(
+				"kind: Kptfile\nmetadata:\n  name: configsync\n"))
+		}
+
+		Expect(client.PkgGetCallerCount).To(Equal(1))
+
+		entries, err := provider.ListCache(context.Background(), cacheOpts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Path).To(Equal("nephio-configsync"))
+		Expect(entries[0].RepoURI).To(Equal(app.DefaultNephioRepoURI))
+
+		pruned, err := provider.PruneCache(context.Background(), cacheOpts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pruned).To(Equal(entries))
+		Expect(provider.ListCache(context.Background(), cacheOpts)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,164 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"path/filepath"
+	"sync"
+
+	"github.com/electrocucaracha/nephioadm/internal/k8s"
+	"github.com/electrocucaracha/nephioadm/internal/kpt"
+	"github.com/electrocucaracha/nephioadm/internal/logging"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+)
+
+// CheckpointFile records the completed package steps of every phase into the base path.
+const CheckpointFile = "nephioadm.checkpoint"
+
+// checkpointData are the completed package steps indexed by phase.
+type checkpointData struct {
+	Phases map[string]*phaseCheckpoint `json:"phases"`
+}
+
+// phaseCheckpoint records the source of the packages and the steps completed on each of them.
+type phaseCheckpoint struct {
+	RepoURI string `json:"repo"`
+	Ref     string `json:"ref,omitempty"`
+	// Steps are the completed steps indexed by component
+	Steps map[string][]string `json:"steps"`
+}
+
+// checkpoint tracks the completed package steps of a phase, so an interrupted phase can be resumed.
+type checkpoint struct {
+	fSys  filesys.FileSystem
+	file  string
+	phase string
+
+	data checkpointData
+	mu   sync.Mutex
+}
+
+// loadCheckpoint reads the checkpoint of the phase stored in the base path. The completed steps are
+// kept when the phase is resumed, otherwise they're discarded.
+func loadCheckpoint(fSys filesys.FileSystem, basePath, phase string, source *phaseCheckpoint,
+	resume bool,
+) (*checkpoint, error) {
+	c := &checkpoint{
+		fSys:  fSys,
+		file:  filepath.Join(basePath, CheckpointFile),
+		phase: phase,
+		data:  checkpointData{Phases: map[string]*phaseCheckpoint{}},
+	}
+
+	if fSys.Exists(c.file) {
+		content, err := fSys.ReadFile(c.file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read the %s checkpoint", c.file)
+		}
+
+		if err := yaml.Unmarshal(content, &c.data); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode the %s checkpoint", c.file)
+		}
+
+		if c.data.Phases == nil {
+			c.data.Phases = map[string]*phaseCheckpoint{}
+		}
+	}
+
+	recorded, ok := c.data.Phases[phase]
+	if resume && ok {
+		if recorded.RepoURI != source.RepoURI || recorded.Ref != source.Ref {
+			return nil, errors.Errorf("the %s phase checkpoint was recorded for the %s repository (ref %q), "+
+				"it can't be resumed from %s (ref %q)", phase, recorded.RepoURI, recorded.Ref, source.RepoURI, source.Ref)
+		}
+
+		if recorded.Steps == nil {
+			recorded.Steps = map[string][]string{}
+		}
+
+		return c, nil
+	}
+
+	c.data.Phases[phase] = &phaseCheckpoint{RepoURI: source.RepoURI, Ref: source.Ref, Steps: map[string][]string{}}
+
+	return c, c.write()
+}
+
+// completed reports whether the step of the component package was completed, it's false when
+// the phase isn't checkpointed.
+func (c *checkpoint) completed(component, step string) bool {
+	if c == nil {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, completed := range c.data.Phases[c.phase].Steps[component] {
+		if completed == step {
+			return true
+		}
+	}
+
+	return false
+}
+
+// complete records the step of the component package as completed.
+func (c *checkpoint) complete(component, step string) error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	steps := c.data.Phases[c.phase].Steps
+	steps[component] = append(steps[component], step)
+
+	return c.write()
+}
+
+// write replaces the checkpoint atomically, so an interruption never leaves it partially written.
+func (c *checkpoint) write() error {
+	content, err := yaml.Marshal(&c.data)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode the checkpoint")
+	}
+
+	if err := c.fSys.MkdirAll(filepath.Dir(c.file)); err != nil {
+		return errors.Wrapf(err, "failed to create the %s checkpoint directory", c.file)
+	}
+
+	return errors.Wrapf(k8s.WriteFile(c.fSys, c.file, content), "failed to write the %s checkpoint", c.file)
+}
+
+// verifyStep checks that the result of a step completed by a previous run is still in place: the
+// package is stored in the base path and, once it's applied, its resources are reconciled.
+func (r *NephioRunner) verifyStep(name string, opts *kpt.CommandOptions) error {
+	if !r.fSys.IsDir(opts.Path) {
+		return errors.Errorf("the %s package was removed since its %s step was completed", opts.Path, name)
+	}
+
+	if name != StepApply || r.cluster == nil {
+		return nil
+	}
+
+	return waitForPackage(r.phaseContext(), r.cluster, r.fSys, opts.Path, r.reconcileTimeout,
+		logging.Writer(opts.Logger))
+}
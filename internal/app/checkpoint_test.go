@@ -0,0 +1,136 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/electrocucaracha/nephioadm/internal/app"
+	"github.com/electrocucaracha/nephioadm/internal/logging"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const interruptedCheckpoint = `phases:
+  init:
+    repo: https://github.com/nephio-project/nephio-packages.git
+    steps:
+      system: [get, customize, render, init, apply]
+      webui: [get]
+`
+
+var _ = Describe("Phase checkpoint", func() {
+	var provider *app.NephioProvider
+	var client *mockClient
+	var fSys filesys.FileSystem
+	var opts *app.NephioRunnerOptions
+	checkpointFile := filepath.Join(app.DefaultBasePath, app.CheckpointFile)
+
+	BeforeEach(func() {
+		fSys = newFakeFileSystem()
+		client = NewMockClient()
+		provider = app.NewProvider(client, fSys, NewMockCluster().newCluster)
+		opts = &app.NephioRunnerOptions{
+			NephioRepoURI: app.DefaultNephioRepoURI,
+			SkipVerify:    true,
+			Resume:        true,
+		}
+	})
+
+	It("should run only the steps which weren't completed", func() {
+		Expect(fSys.WriteFile(checkpointFile, []byte(interruptedCheckpoint))).To(Succeed())
+
+		Expect(provider.Init(context.Background(), opts)).To(Succeed())
+		Expect(client.Commands).To(Equal([]string{
+			"fn render " + filepath.Join(app.DefaultBasePath, "webui"),
+			"live init " + filepath.Join(app.DefaultBasePath, "webui"),
+			"live apply " + filepath.Join(app.DefaultBasePath, "webui"),
+		}))
+
+		content, err := fSys.ReadFile(checkpointFile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(content)).To(ContainSubstring("webui:\n      - get\n      - customize\n      - render\n" +
+			"      - init\n      - apply\n"))
+	})
+
+	It("should verify the steps of a completed phase", func() {
+		opts.Resume = false
+		Expect(provider.Init(context.Background(), opts)).To(Succeed())
+		client.Commands = nil
+
+		opts.Resume = true
+		Expect(provider.Init(context.Background(), opts)).To(Succeed())
+		Expect(client.Commands).To(BeEmpty())
+	})
+
+	It("should run every step when the phase isn't resumed", func() {
+		Expect(fSys.WriteFile(checkpointFile, []byte(interruptedCheckpoint))).To(Succeed())
+		opts.Resume = false
+
+		Expect(provider.Init(context.Background(), opts)).To(Succeed())
+		Expect(client.PkgGetCallerCount).To(Equal(2))
+		Expect(client.LiveApplyCallerCount).To(Equal(2))
+	})
+
+	It("should report the stuck resources of the completed packages through the phase logger", func() {
+		Expect(fSys.WriteFile(checkpointFile, []byte(interruptedCheckpoint))).To(Succeed())
+		Expect(fSys.WriteFile(filepath.Join(app.DefaultBasePath, "system", "resourcegroup.yaml"),
+			[]byte(systemInventory))).To(Succeed())
+
+		cluster := NewMockCluster()
+		cluster.AddObject("resourcegroups", &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "inventory-system", "namespace": "nephio-system"},
+			"spec": map[string]interface{}{"resources": []interface{}{map[string]interface{}{
+				"group": "apps", "kind": "Deployment", "namespace": "nephio-system", "name": "package-deployment-controller",
+			}}},
+		}})
+		cluster.AddObject("deployments", newDeployment(0))
+
+		var out bytes.Buffer
+		logger, err := logging.NewLogger(&out, logging.FormatJSON, logging.LevelInfo)
+		Expect(err).NotTo(HaveOccurred())
+		provider = app.NewProvider(client, fSys, cluster.newCluster)
+		provider.SetLogger(logger)
+		opts.ReconcileTimeout = 10 * time.Millisecond
+
+		Expect(provider.Init(context.Background(), opts)).To(MatchError(ContainSubstring("aren't reconciled")))
+		Expect(out.String()).To(MatchRegexp(`"msg":"Deployment.apps nephio-system/package-deployment-controller +` +
+			`InProgress +Available: 0/1","phase":"init","cluster":"","package":"system"`))
+	})
+
+	DescribeTable("resume refusal", func(setup func(), expected string) {
+		Expect(fSys.WriteFile(checkpointFile, []byte(interruptedCheckpoint))).To(Succeed())
+		setup()
+
+		Expect(provider.Init(context.Background(), opts)).To(MatchError(ContainSubstring(expected)))
+	},
+		Entry("when the completed package was removed", func() {
+			Expect(fSys.RemoveAll(filepath.Join(app.DefaultBasePath, "system"))).To(Succeed())
+		}, "package was removed since its get step was completed"),
+		Entry("when the packages are fetched from another repository", func() {
+			opts.NephioRepoURI = "/nephio-packages"
+		}, "it can't be resumed from /nephio-packages"),
+		Entry("when the checkpoint is corrupted", func() {
+			Expect(fSys.WriteFile(checkpointFile, []byte("phases: ["))).To(Succeed())
+		}, "failed to decode the"),
+	)
+})
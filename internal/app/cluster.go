@@ -0,0 +1,161 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/electrocucaracha/nephioadm/internal/k8s"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	DefaultClusterRecordNamespace = "nephio-system"
+	DefaultRepositoryNamespace    = "default"
+	WorkloadClusterLabel          = "nephioadm.nephio.org/workload-cluster"
+	RegionLabel                   = "topology.kubernetes.io/region"
+)
+
+var (
+	repositoryGVR = schema.GroupVersionResource{
+		Group: "config.porch.kpt.dev", Version: "v1alpha1", Resource: "repositories",
+	}
+	configMapGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+)
+
+// WorkloadCluster describes a cluster joined to the Nephio management cluster.
+type WorkloadCluster struct {
+	Name   string
+	Region string
+	Repo   string
+	Labels map[string]string
+}
+
+// workloadRepo returns the repository of the Git service the workload cluster syncs from, so
+// the one registered in the management cluster and the ConfigSync one don't differ.
+func workloadRepo(gitServiceURI, name string) string {
+	return strings.TrimSuffix(gitServiceURI, "/") + "/" + name
+}
+
+func newWorkloadCluster(opts *NephioRunnerOptions) *WorkloadCluster {
+	return &WorkloadCluster{
+		Name:   opts.ClusterName,
+		Region: opts.ClusterRegion,
+		Repo:   workloadRepo(opts.GitServiceURI, opts.ClusterName),
+		Labels: opts.ClusterLabels,
+	}
+}
+
+func (w WorkloadCluster) repository() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": repositoryGVR.GroupVersion().String(),
+		"kind":       "Repository",
+		"metadata": map[string]interface{}{
+			"name":      w.Name,
+			"namespace": DefaultRepositoryNamespace,
+		},
+		"spec": map[string]interface{}{
+			"content":    "Package",
+			"deployment": true,
+			"type":       "git",
+			"git": map[string]interface{}{
+				"repo":      w.Repo,
+				"branch":    "main",
+				"directory": "/",
+			},
+		},
+	}}
+}
+
+func (w WorkloadCluster) record() *unstructured.Unstructured {
+	labels := map[string]interface{}{WorkloadClusterLabel: "true"}
+	for key, value := range w.Labels {
+		labels[key] = value
+	}
+
+	if len(w.Region) != 0 {
+		labels[RegionLabel] = w.Region
+	}
+
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": configMapGVR.GroupVersion().String(),
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":      w.Name,
+			"namespace": DefaultClusterRecordNamespace,
+			"labels":    labels,
+		},
+		"data": map[string]interface{}{
+			"clusterName": w.Name,
+			"region":      w.Region,
+			"repo":        w.Repo,
+		},
+	}}
+}
+
+func workloadClusterFromRecord(record *unstructured.Unstructured) WorkloadCluster {
+	data, _, _ := unstructured.NestedStringMap(record.Object, "data")
+
+	labels := map[string]string{}
+	for key, value := range record.GetLabels() {
+		if key != WorkloadClusterLabel && key != RegionLabel {
+			labels[key] = value
+		}
+	}
+
+	return WorkloadCluster{
+		Name:   data["clusterName"],
+		Region: data["region"],
+		Repo:   data["repo"],
+		Labels: labels,
+	}
+}
+
+// registerWorkloadCluster records the workload cluster and its deployment repository in the management cluster.
+func registerWorkloadCluster(ctx context.Context, mgmt k8s.ClusterClient, cluster *WorkloadCluster) error {
+	if err := mgmt.ApplyResource(ctx, repositoryGVR, cluster.repository()); err != nil {
+		return errors.Wrapf(err, "failed to create the %s deployment repository", cluster.Name)
+	}
+
+	if err := mgmt.ApplyResource(ctx, configMapGVR, cluster.record()); err != nil {
+		return errors.Wrapf(err, "failed to record the %s workload cluster", cluster.Name)
+	}
+
+	return nil
+}
+
+// listWorkloadClusters retrieves the workload clusters recorded in the management cluster.
+func listWorkloadClusters(ctx context.Context, mgmt k8s.ClusterClient) ([]WorkloadCluster, error) {
+	records, err := mgmt.ListResources(ctx, configMapGVR, DefaultClusterRecordNamespace,
+		WorkloadClusterLabel+"=true")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve the workload cluster records")
+	}
+
+	clusters := make([]WorkloadCluster, 0, len(records))
+	for i := range records {
+		clusters = append(clusters, workloadClusterFromRecord(&records[i]))
+	}
+
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i].Name < clusters[j].Name })
+
+	return clusters, nil
+}
@@ -0,0 +1,166 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// task is a node of the installation DAG, it runs once all its dependencies succeed.
+type task struct {
+	name      string
+	dependsOn []string
+	run       func() error
+}
+
+// taskGraph indexes the dependents and the number of pending dependencies of every task.
+type taskGraph struct {
+	dependents [][]int
+	pending    []int
+}
+
+// newTaskGraph validates the dependencies of the tasks provided and builds their graph.
+func newTaskGraph(tasks []task) (*taskGraph, error) {
+	index := make(map[string]int, len(tasks))
+
+	for i, t := range tasks {
+		if _, ok := index[t.name]; ok {
+			return nil, errors.Errorf("the %s task is duplicated", t.name)
+		}
+
+		index[t.name] = i
+	}
+
+	graph := &taskGraph{dependents: make([][]int, len(tasks)), pending: make([]int, len(tasks))}
+
+	for i, t := range tasks {
+		for _, dependency := range t.dependsOn {
+			j, ok := index[dependency]
+			if !ok {
+				return nil, errors.Errorf("the %s task depends on the unknown %s task", t.name, dependency)
+			}
+
+			graph.dependents[j] = append(graph.dependents[j], i)
+			graph.pending[i]++
+		}
+	}
+
+	// Every task has to be reachable by resolving the dependencies in order, otherwise there is a cycle
+	pending := append([]int{}, graph.pending...)
+	ready := graph.ready()
+	resolved := 0
+
+	for ; len(ready) != 0; resolved++ {
+		i := ready[0]
+		ready = ready[1:]
+
+		for _, j := range graph.dependents[i] {
+			if pending[j]--; pending[j] == 0 {
+				ready = append(ready, j)
+			}
+		}
+	}
+
+	if resolved != len(tasks) {
+		return nil, errors.New("the task dependencies contain a cycle")
+	}
+
+	return graph, nil
+}
+
+// ready returns the tasks without dependencies.
+func (g *taskGraph) ready() []int {
+	ready := []int{}
+
+	for i, pending := range g.pending {
+		if pending == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	return ready
+}
+
+type taskResult struct {
+	index int
+	err   error
+}
+
+// runTasks runs the tasks once their dependencies succeed, up to parallelism tasks at a time.
+// The ready tasks are started in the order provided, so they run in that order when the
+// parallelism is 1. Once a task fails, or the context is cancelled, no more tasks are started
+// and the first failure is returned after the running ones finish.
+func runTasks(ctx context.Context, tasks []task, parallelism int) error {
+	graph, err := newTaskGraph(tasks)
+	if err != nil {
+		return err
+	}
+
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	results := make(chan taskResult)
+	ready := graph.ready()
+	running := 0
+
+	var failure error
+
+	for {
+		for failure == nil && ctx.Err() == nil && running < parallelism && len(ready) != 0 {
+			i := ready[0]
+			ready = ready[1:]
+			running++
+
+			go func(i int) {
+				results <- taskResult{index: i, err: tasks[i].run()}
+			}(i)
+		}
+
+		if running == 0 {
+			break
+		}
+
+		result := <-results
+		running--
+
+		if result.err != nil {
+			if failure == nil {
+				failure = result.err
+			}
+
+			continue
+		}
+
+		for _, j := range graph.dependents[result.index] {
+			if graph.pending[j]--; graph.pending[j] == 0 {
+				ready = append(ready, j)
+			}
+		}
+
+		sort.Ints(ready)
+	}
+
+	if failure != nil {
+		return failure
+	}
+
+	return errors.Wrap(ctx.Err(), "the installation was aborted")
+}
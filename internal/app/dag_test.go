@@ -0,0 +1,161 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// taskRecorder records the tasks run and the maximum number of them running at the same time.
+type taskRecorder struct {
+	mu         sync.Mutex
+	run        []string
+	running    int
+	maxRunning int
+}
+
+func (r *taskRecorder) task(name string, run func() error, dependsOn ...string) task {
+	return task{name: name, dependsOn: dependsOn, run: func() error {
+		r.mu.Lock()
+		r.run = append(r.run, name)
+		r.running++
+		if r.running > r.maxRunning {
+			r.maxRunning = r.running
+		}
+		r.mu.Unlock()
+
+		defer func() {
+			r.mu.Lock()
+			r.running--
+			r.mu.Unlock()
+		}()
+
+		if run == nil {
+			return nil
+		}
+
+		return run()
+	}}
+}
+
+var _ = Describe("Task DAG", func() {
+	var recorder *taskRecorder
+
+	BeforeEach(func() {
+		recorder = &taskRecorder{}
+	})
+
+	DescribeTable("invalid graphs", func(expected string, tasks ...task) {
+		err := runTasks(context.Background(), tasks, 1)
+
+		Expect(err).To(MatchError(expected))
+		Expect(recorder.run).To(BeEmpty())
+	},
+		Entry("when the dependencies contain a cycle", "the task dependencies contain a cycle",
+			task{name: "a"}, task{name: "b", dependsOn: []string{"c"}}, task{name: "c", dependsOn: []string{"b"}}),
+		Entry("when a task depends on itself", "the task dependencies contain a cycle",
+			task{name: "a", dependsOn: []string{"a"}}),
+		Entry("when a dependency is unknown", "the b task depends on the unknown c task",
+			task{name: "a"}, task{name: "b", dependsOn: []string{"c"}}),
+		Entry("when a task is duplicated", "the a task is duplicated", task{name: "a"}, task{name: "a"}),
+	)
+
+	It("should run the tasks in the order provided when the parallelism is 1", func() {
+		tasks := []task{
+			recorder.task("c", nil, "a"),
+			recorder.task("a", nil),
+			recorder.task("b", nil),
+			recorder.task("d", nil, "c", "b"),
+		}
+
+		Expect(runTasks(context.Background(), tasks, 1)).To(Succeed())
+
+		Expect(recorder.run).To(Equal([]string{"a", "c", "b", "d"}))
+		Expect(recorder.maxRunning).To(Equal(1))
+	})
+
+	It("should not run more tasks than the parallelism", func() {
+		started := make(chan string)
+		release := make(chan struct{})
+		wait := func() error {
+			started <- ""
+			<-release
+
+			return nil
+		}
+		tasks := []task{
+			recorder.task("a", wait), recorder.task("b", wait), recorder.task("c", wait), recorder.task("d", wait),
+		}
+		done := make(chan error)
+
+		go func() {
+			done <- runTasks(context.Background(), tasks, 2)
+		}()
+
+		// The tasks are only started when a slot is released, so two of them run on every round
+		for round := 0; round < 2; round++ {
+			<-started
+			<-started
+			Expect(started).NotTo(Receive())
+
+			release <- struct{}{}
+			release <- struct{}{}
+		}
+
+		Expect(<-done).To(Succeed())
+		Expect(recorder.run).To(ConsistOf("a", "b", "c", "d"))
+		Expect(recorder.maxRunning).To(Equal(2))
+	})
+
+	It("should skip the dependents of the failed tasks", func() {
+		tasks := []task{
+			recorder.task("a", func() error { return errors.New("a failed") }),
+			recorder.task("b", nil, "a"),
+			recorder.task("c", nil),
+		}
+
+		Expect(runTasks(context.Background(), tasks, 1)).To(MatchError("a failed"))
+
+		Expect(recorder.run).To(Equal([]string{"a"}))
+	})
+
+	It("should not start more tasks once the context is cancelled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		tasks := []task{
+			recorder.task("a", func() error {
+				cancel()
+
+				return nil
+			}),
+			recorder.task("b", nil),
+			recorder.task("c", nil, "a"),
+		}
+
+		err := runTasks(ctx, tasks, 1)
+
+		Expect(err).To(MatchError(ContainSubstring("the installation was aborted")))
+		Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+		Expect(recorder.run).To(Equal([]string{"a"}))
+	})
+})
@@ -0,0 +1,264 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/electrocucaracha/nephioadm/internal/k8s"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	k8sYaml "sigs.k8s.io/yaml"
+)
+
+const managementClusterName = "management"
+
+// FleetCluster selects a cluster of the fleet and overrides the default packages settings.
+type FleetCluster struct {
+	Kubeconfig    string `json:"kubeconfig,omitempty"`
+	Context       string `json:"context"`
+	BasePath      string `json:"basePath,omitempty"`
+	NephioRepoURI string `json:"nephioRepo,omitempty"`
+	GitServiceURI string `json:"gitService,omitempty"`
+}
+
+// FleetManagement is the cluster where the Nephio control plane is installed.
+type FleetManagement struct {
+	FleetCluster
+	BackendBaseUrl   string `json:"backendBaseUrl,omitempty"`
+	WebUIClusterType string `json:"webuiClusterType,omitempty"`
+}
+
+// FleetWorkload is a cluster joined to the management cluster.
+type FleetWorkload struct {
+	FleetCluster
+	// Name registers the cluster in the management cluster, the context is used by default
+	Name   string            `json:"name,omitempty"`
+	Region string            `json:"region,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Fleet describes a management cluster and the workload clusters joined to it.
+type Fleet struct {
+	Management FleetManagement `json:"management"`
+	Workloads  []FleetWorkload `json:"workloads"`
+}
+
+type FleetOptions struct {
+	File string
+	// Defaults are the options of the clusters which don't override them
+	Defaults NephioRunnerOptions
+	// JoinConcurrency limits the workload clusters joined at the same time, all of them are joined
+	// concurrently when it isn't set
+	JoinConcurrency int
+}
+
+// ClusterResult reports the phase run on a cluster of the fleet.
+type ClusterResult struct {
+	Name     string
+	Context  string
+	Phase    string
+	Outcome  string
+	Duration time.Duration
+	Err      error
+}
+
+// ReadFleet reads and validates the fleet file provided.
+func ReadFleet(fSys filesys.FileSystem, file string) (*Fleet, error) {
+	data, err := fSys.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the %s fleet file", file)
+	}
+
+	fleet := &Fleet{}
+	if err := k8sYaml.UnmarshalStrict(data, fleet); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the %s fleet file", file)
+	}
+
+	if len(fleet.Management.Context) == 0 {
+		return nil, errors.New("the management cluster context is required")
+	}
+
+	names := map[string]bool{}
+
+	for i := range fleet.Workloads {
+		workload := &fleet.Workloads[i]
+		if len(workload.Context) == 0 {
+			return nil, errors.Errorf("the context of the workload cluster %d is required", i+1)
+		}
+
+		if len(workload.Name) == 0 {
+			workload.Name = workload.Context
+		}
+
+		if names[workload.Name] {
+			return nil, errors.Errorf("the %s workload cluster is duplicated", workload.Name)
+		}
+
+		names[workload.Name] = true
+	}
+
+	return fleet, nil
+}
+
+// clusterFile adds the cluster name to the file provided, so every cluster writes its own file.
+func clusterFile(file, name string) string {
+	if len(file) == 0 {
+		return ""
+	}
+
+	ext := filepath.Ext(file)
+
+	return strings.TrimSuffix(file, ext) + "-" + name + ext
+}
+
+func (c FleetCluster) runnerOptions(defaults *NephioRunnerOptions, name, basePath string) *NephioRunnerOptions {
+	opts := *defaults
+	opts.Cluster = k8s.ClusterOptions{Kubeconfig: c.Kubeconfig, Context: c.Context}
+	opts.BasePath = basePath
+	opts.ReportFile = clusterFile(defaults.ReportFile, name)
+	opts.JUnitFile = clusterFile(defaults.JUnitFile, name)
+
+	if len(c.BasePath) != 0 {
+		opts.BasePath = c.BasePath
+	}
+
+	if len(c.NephioRepoURI) != 0 {
+		opts.NephioRepoURI = c.NephioRepoURI
+	}
+
+	if len(c.GitServiceURI) != 0 {
+		opts.GitServiceURI = c.GitServiceURI
+	}
+
+	return &opts
+}
+
+func (f Fleet) initOptions(defaults *NephioRunnerOptions) *NephioRunnerOptions {
+	opts := f.Management.runnerOptions(defaults, managementClusterName, defaults.BasePath)
+
+	if len(f.Management.BackendBaseUrl) != 0 {
+		opts.BackendBaseUrl = f.Management.BackendBaseUrl
+	}
+
+	if len(f.Management.WebUIClusterType) != 0 {
+		opts.WebUIClusterType = f.Management.WebUIClusterType
+	}
+
+	return opts
+}
+
+// joinOptions returns the options of the workload cluster, whose packages are stored in a
+// subdirectory of the default base path named after it.
+func (f Fleet) joinOptions(defaults *NephioRunnerOptions, workload *FleetWorkload) *NephioRunnerOptions {
+	basePath := defaults.BasePath
+	if len(basePath) == 0 {
+		basePath = DefaultBasePath
+	}
+
+	opts := workload.runnerOptions(defaults, workload.Name, filepath.Join(basePath, workload.Name))
+	opts.MgmtKubeconfig = f.Management.Kubeconfig
+	opts.MgmtContext = f.Management.Context
+	opts.ClusterName = workload.Name
+	opts.ClusterRegion = workload.Region
+	opts.ClusterLabels = workload.Labels
+
+	return opts
+}
+
+func runClusterPhase(name, clusterContext, phase string, run func() error) ClusterResult {
+	start := time.Now()
+	err := run()
+
+	result := ClusterResult{
+		Name: name, Context: clusterContext, Phase: phase, Duration: time.Since(start).Round(time.Second), Err: err,
+	}
+	result.Outcome, _ = outcome(err)
+
+	return result
+}
+
+// Apply installs the Nephio control plane on the management cluster of the fleet file and then
+// joins the workload clusters concurrently. The workload clusters are skipped when the
+// management cluster installation fails.
+func (p NephioProvider) Apply(ctx context.Context, opts *FleetOptions) ([]ClusterResult, error) {
+	fleet, err := ReadFleet(p.fSys, opts.File)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]ClusterResult, 1+len(fleet.Workloads))
+	results[0] = runClusterPhase(managementClusterName, fleet.Management.Context, PhaseInit, func() error {
+		return p.Init(ctx, fleet.initOptions(&opts.Defaults))
+	})
+
+	if results[0].Err != nil {
+		for i, workload := range fleet.Workloads {
+			results[i+1] = ClusterResult{
+				Name: workload.Name, Context: workload.Context, Phase: PhaseJoin, Outcome: OutcomeSkipped,
+			}
+		}
+
+		return results, errors.Wrap(results[0].Err, "failed to install the management cluster")
+	}
+
+	concurrency := opts.JoinConcurrency
+	if concurrency < 1 {
+		concurrency = len(fleet.Workloads)
+	}
+
+	semaphore := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+
+	for i := range fleet.Workloads {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			workload := &fleet.Workloads[i]
+			results[i+1] = runClusterPhase(workload.Name, workload.Context, PhaseJoin, func() error {
+				return p.Join(ctx, fleet.joinOptions(&opts.Defaults, workload))
+			})
+		}(i)
+	}
+
+	wg.Wait()
+
+	failed := []string{}
+
+	for _, result := range results[1:] {
+		if result.Err != nil {
+			failed = append(failed, result.Name)
+		}
+	}
+
+	if len(failed) != 0 {
+		return results, errors.Errorf("%d of %d workload clusters failed to join: %s", len(failed),
+			len(fleet.Workloads), strings.Join(failed, ", "))
+	}
+
+	return results, nil
+}
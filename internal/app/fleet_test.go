@@ -0,0 +1,155 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/electrocucaracha/nephioadm/internal/app"
+	"github.com/electrocucaracha/nephioadm/internal/k8s"
+	"github.com/electrocucaracha/nephioadm/internal/logging"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const fleetFile = `management:
+  context: kind-mgmt
+  webuiClusterType: LoadBalancer
+workloads:
+  - context: kind-edge-1
+    region: us-east
+  - context: kind-edge-2
+    name: edge-2
+    gitService: http://gitea:3000/edge
+`
+
+var _ = Describe("Fleet", func() {
+	var provider *app.NephioProvider
+	var client *mockClient
+	var cluster *mockCluster
+	var fSys filesys.FileSystem
+	var opts *app.FleetOptions
+
+	BeforeEach(func() {
+		basePath := GinkgoT().TempDir()
+		fSys = k8s.MakeFsOnDisk()
+		Expect(fSys.WriteFile(filepath.Join(basePath, "fleet.yaml"), []byte(fleetFile))).To(Succeed())
+		writePackageFiles(fSys, basePath)
+
+		client = NewMockClient()
+		client.OnCommand = func(command string) {
+			// kpt creates the package directory when it is fetched
+			if path, ok := strings.CutPrefix(command, "pkg get "); ok {
+				Expect(fSys.MkdirAll(path)).To(Succeed())
+			}
+		}
+		cluster = NewMockCluster()
+		provider = app.NewProvider(client, fSys, cluster.newCluster)
+		opts = &app.FleetOptions{
+			File:     filepath.Join(basePath, "fleet.yaml"),
+			Defaults: app.NephioRunnerOptions{BasePath: basePath, SkipVerify: true},
+		}
+	})
+
+	It("should init the management cluster and join the workload clusters", func() {
+		results, err := provider.Apply(context.Background(), opts)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(3))
+		Expect(results[0]).To(MatchFields(IgnoreExtras, Fields{
+			"Name": Equal("management"), "Context": Equal("kind-mgmt"), "Phase": Equal(app.PhaseInit),
+			"Outcome": Equal(app.OutcomePassed),
+		}))
+		Expect(results[1]).To(MatchFields(IgnoreExtras, Fields{
+			"Name": Equal("kind-edge-1"), "Context": Equal("kind-edge-1"), "Phase": Equal(app.PhaseJoin),
+			"Outcome": Equal(app.OutcomePassed),
+		}))
+		Expect(results[2].Name).To(Equal("edge-2"))
+
+		basePath := opts.Defaults.BasePath
+		Expect(client.Commands).To(ContainElements(
+			"live apply "+filepath.Join(basePath, "system"),
+			"live apply "+filepath.Join(basePath, "webui"),
+			"live apply "+filepath.Join(basePath, "kind-edge-1", "configsync"),
+			"live apply "+filepath.Join(basePath, "edge-2", "configsync"),
+		))
+		Expect(cluster.Resources).To(HaveKey("Repository/kind-edge-1"))
+		Expect(cluster.Resources["Repository/edge-2"].Object).To(HaveKeyWithValue("spec",
+			HaveKeyWithValue("git", HaveKeyWithValue("repo", "http://gitea:3000/edge/edge-2"))))
+		Expect(client.FnEvalValues).To(ConsistOf(
+			HaveSuffix("/kind-edge-1"), "http://gitea:3000/edge/edge-2"))
+	})
+
+	It("should write the logs of every workload cluster into its own files", func() {
+		logger, err := logging.NewLogger(io.Discard, logging.FormatText, logging.LevelInfo)
+		Expect(err).NotTo(HaveOccurred())
+		provider.SetLogger(logger)
+		opts.Defaults.WriteLogs = true
+
+		_, err = provider.Apply(context.Background(), opts)
+		Expect(err).NotTo(HaveOccurred())
+
+		for name, cluster := range map[string]string{"kind-edge-1": "kind-edge-1", "edge-2": "kind-edge-2"} {
+			logs, err := filepath.Glob(filepath.Join(opts.Defaults.BasePath, name, app.LogsDir, "join-*.log"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(logs).To(HaveLen(1))
+
+			content, err := fSys.ReadFile(logs[0])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(strings.Split(strings.TrimSpace(string(content)), "\n")).To(HaveEach(
+				ContainSubstring(`"phase"="join" "cluster"="%s"`, cluster)))
+			Expect(string(content)).To(ContainSubstring(
+				`"msg"="Fetching package" "phase"="join" "cluster"="%s" "package"="configsync"`, cluster))
+		}
+	})
+
+	It("should skip the workload clusters when the management cluster fails", func() {
+		client.LiveApplyErr = errors.New("apply failed")
+
+		results, err := provider.Apply(context.Background(), opts)
+
+		Expect(err).To(MatchError(ContainSubstring("failed to install the management cluster")))
+		Expect(results[0].Outcome).To(Equal(app.OutcomeFailed))
+		Expect(results[1].Outcome).To(Equal(app.OutcomeSkipped))
+		Expect(results[2].Outcome).To(Equal(app.OutcomeSkipped))
+	})
+
+	DescribeTable("fleet file validation", func(content, expected string) {
+		file := filepath.Join(GinkgoT().TempDir(), "fleet.yaml")
+		Expect(fSys.WriteFile(file, []byte(content))).To(Succeed())
+
+		_, err := app.ReadFleet(fSys, file)
+
+		Expect(err).To(MatchError(ContainSubstring(expected)))
+	},
+		Entry("when the management context is missing", "workloads: []\n",
+			"the management cluster context is required"),
+		Entry("when a workload context is missing", "management:\n  context: kind-mgmt\nworkloads:\n  - name: edge\n",
+			"the context of the workload cluster 1 is required"),
+		Entry("when a workload cluster is duplicated",
+			"management:\n  context: kind-mgmt\nworkloads:\n  - context: kind-edge\n  - context: kind-edge\n",
+			"the kind-edge workload cluster is duplicated"),
+		Entry("when an unknown field is provided", "management:\n  context: kind-mgmt\n  unknown: true\n",
+			"unknown field"),
+	)
+})
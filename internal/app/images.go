@@ -0,0 +1,163 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+
+	"github.com/electrocucaracha/nephioadm/internal/k8s"
+	"github.com/pkg/errors"
+)
+
+// Image types, the container images are pulled by the cluster and the function images by kpt.
+const (
+	ImageTypeContainer = "container"
+	ImageTypeFunction  = "function"
+)
+
+type ImagesOptions struct {
+	// Runner defines the sources and customizations of the packages
+	Runner NephioRunnerOptions
+	// Phase limits the images to the components installed on it, all of them are listed when it's empty
+	Phase string
+}
+
+// ImageInfo is an image pulled during the installation of the Nephio components.
+type ImageInfo struct {
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	Components []string `json:"components"`
+}
+
+// imageIndex indexes the components using every image by type and name.
+type imageIndex map[string]map[string][]string
+
+func (idx imageIndex) add(imageType, name, component string) {
+	if idx[imageType] == nil {
+		idx[imageType] = map[string][]string{}
+	}
+
+	for _, existing := range idx[imageType][name] {
+		if existing == component {
+			return
+		}
+	}
+
+	idx[imageType][name] = append(idx[imageType][name], component)
+}
+
+// names returns the sorted names of the images of the type provided.
+func (idx imageIndex) names(imageType string) []string {
+	names := make([]string, 0, len(idx[imageType]))
+	for name := range idx[imageType] {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// list returns the images sorted by type and name.
+func (idx imageIndex) list() []ImageInfo {
+	images := []ImageInfo{}
+
+	for _, imageType := range []string{ImageTypeContainer, ImageTypeFunction} {
+		for _, name := range idx.names(imageType) {
+			images = append(images, ImageInfo{Name: name, Type: imageType, Components: idx[imageType][name]})
+		}
+	}
+
+	return images
+}
+
+// collectImages indexes the images of the component package workloads and of the kpt functions
+// run on it, both the ones of its Kptfile pipelines and the ones evaluated by the runner.
+func (r *NephioRunner) collectImages(idx imageIndex, component string) error {
+	path := r.packagePath(component)
+
+	functions, err := k8s.FunctionImages(r.fSys, path)
+	if err != nil {
+		return err
+	}
+
+	if component == ComponentConfigSync {
+		functions = append(functions, r.imageMirror.Rewrite(searchReplaceImage))
+	}
+
+	for _, image := range functions {
+		idx.add(ImageTypeFunction, image, component)
+	}
+
+	pkg, err := k8s.ReadPackage(r.fSys, path)
+	if err != nil {
+		return err
+	}
+
+	for _, image := range pkg.Images() {
+		idx.add(ImageTypeContainer, image, component)
+	}
+
+	return nil
+}
+
+// ListImages fetches and renders the component packages into a work directory under the base
+// path, and returns the images of their workloads and kpt functions.
+func (p NephioProvider) ListImages(ctx context.Context, opts *ImagesOptions) ([]ImageInfo, error) {
+	components := allComponents()
+	if len(opts.Phase) != 0 {
+		phaseList, ok := phaseComponents[opts.Phase]
+		if !ok {
+			return nil, errors.Errorf("unknown %q phase", opts.Phase)
+		}
+
+		components = phaseList
+	}
+
+	runnerOpts := opts.Runner
+	if len(runnerOpts.BasePath) == 0 {
+		runnerOpts.BasePath = DefaultBasePath
+	}
+
+	// The packages are fetched into a work directory, without updating the lock file of the installations
+	runnerOpts.BasePath = filepath.Join(runnerOpts.BasePath, ".images")
+	runnerOpts.LockFile = ""
+	if err := p.fSys.RemoveAll(runnerOpts.BasePath); err != nil {
+		return nil, errors.Wrapf(err, "failed to remove the stale %s work directory", runnerOpts.BasePath)
+	}
+	defer p.fSys.RemoveAll(runnerOpts.BasePath)
+
+	runner, err := p.newPackageRunner(ctx, &runnerOpts, p.log)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := imageIndex{}
+
+	for _, component := range components {
+		if err := runner.preparePackage(component); err != nil {
+			return nil, err
+		}
+
+		if err := runner.collectImages(idx, component); err != nil {
+			return nil, err
+		}
+	}
+
+	return idx.list(), nil
+}
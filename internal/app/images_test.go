@@ -0,0 +1,103 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package app_test
+
+import (
+	"context"
+
+	"github.com/electrocucaracha/nephioadm/internal/app"
+	"github.com/electrocucaracha/nephioadm/internal/k8s"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+var _ = Describe("Images", func() {
+	var provider *app.NephioProvider
+	var client *mockClient
+	var fSys filesys.FileSystem
+
+	BeforeEach(func() {
+		fSys = filesys.MakeFsInMemory()
+		Expect(fSys.WriteFile("/src/nephio-packages/nephio-configsync/Kptfile", []byte(`apiVersion: kpt.dev/v1
+kind: Kptfile
+metadata:
+  name: nephio-configsync
+pipeline:
+  mutators:
+  - image: gcr.io/kpt-fn/set-namespace:v0.4.1
+`))).To(Succeed())
+		Expect(fSys.WriteFile("/src/nephio-packages/nephio-configsync/reconciler-manager.yaml", []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: reconciler-manager
+spec:
+  template:
+    spec:
+      containers:
+      - name: reconciler-manager
+        image: gcr.io/config-management-release/reconciler-manager:v1.14.2
+      - name: otel-agent
+        image: gcr.io/config-management-release/otelcontribcol:v0.54.0
+`))).To(Succeed())
+
+		client = NewMockClient()
+		provider = app.NewProvider(client, fSys, NewMockCluster().newCluster)
+	})
+
+	It("should list the images of the rendered packages", func() {
+		images, err := provider.ListImages(context.Background(), &app.ImagesOptions{
+			Runner: app.NephioRunnerOptions{
+				BasePath:      "/opt/nephio",
+				NephioRepoURI: "/src/nephio-packages",
+				ImageMirror:   k8s.ImageMirror{Rules: []k8s.ImageRule{{From: "gcr.io/kpt-fn", To: "mirror.local/kpt-fn"}}},
+			},
+			Phase: app.PhaseJoin,
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(images).To(Equal([]app.ImageInfo{
+			{
+				Name:       "gcr.io/config-management-release/otelcontribcol:v0.54.0",
+				Type:       app.ImageTypeContainer,
+				Components: []string{app.ComponentConfigSync},
+			},
+			{
+				Name:       "gcr.io/config-management-release/reconciler-manager:v1.14.2",
+				Type:       app.ImageTypeContainer,
+				Components: []string{app.ComponentConfigSync},
+			},
+			{
+				Name:       "mirror.local/kpt-fn/search-replace:v0.2",
+				Type:       app.ImageTypeFunction,
+				Components: []string{app.ComponentConfigSync},
+			},
+			{
+				Name:       "mirror.local/kpt-fn/set-namespace:v0.4.1",
+				Type:       app.ImageTypeFunction,
+				Components: []string{app.ComponentConfigSync},
+			},
+		}))
+		Expect(client.FnRenderCallerCount).To(Equal(1))
+		Expect(fSys.Exists("/opt/nephio/.images")).To(BeFalse())
+	})
+
+	It("should fail when the phase is unknown", func() {
+		_, err := provider.ListImages(context.Background(), &app.ImagesOptions{Phase: "unknown"})
+
+		Expect(err).To(MatchError(`unknown "unknown" phase`))
+	})
+})
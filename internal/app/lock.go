@@ -0,0 +1,115 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"github.com/electrocucaracha/nephioadm/internal/kpt"
+	"github.com/electrocucaracha/nephioadm/internal/lock"
+	"github.com/electrocucaracha/nephioadm/internal/logging"
+	"github.com/pkg/errors"
+)
+
+// loadLock reads the lock file of the runner, it's required when the installation is locked.
+func (r *NephioRunner) loadLock(locked bool) error {
+	r.locked = locked
+
+	if !r.fSys.Exists(r.lockFile) {
+		if locked {
+			return errors.Errorf("the %s lock file is required by the locked installation", r.lockFile)
+		}
+
+		return nil
+	}
+
+	lockFile, err := lock.Read(r.fSys, r.lockFile)
+	if err != nil {
+		return err
+	}
+
+	r.lock = lockFile
+
+	return nil
+}
+
+// packageOptions returns the source of the component package, which is pinned to the locked
+// commit when the installation is locked.
+func (r *NephioRunner) packageOptions(component string) (*kpt.PackageOptions, error) {
+	pkgOpts := &kpt.PackageOptions{RepoURI: r.repoURI, Path: componentPackages[component], Version: r.repoRef}
+	if !r.locked {
+		return pkgOpts, nil
+	}
+
+	locked, ok := r.lock.Get(component)
+	if !ok {
+		return nil, errors.Errorf("the %s package isn't locked in the %s file", component, r.lockFile)
+	}
+
+	if len(locked.Commit) != 0 {
+		pkgOpts.Version = locked.Commit
+	}
+
+	return pkgOpts, nil
+}
+
+// lockPackage records the revision and content of the fetched component package into the lock
+// file, or verifies them against the locked ones when the installation is locked. The packages
+// fetched by previous runs are skipped, since their content could be already customized.
+func (r *NephioRunner) lockPackage(component string, pkgOpts *kpt.PackageOptions, fetched bool) error {
+	path := r.packagePath(component)
+
+	if !fetched {
+		if r.locked {
+			return errors.Errorf("the %s package was already fetched into %s, its content can't be verified "+
+				"against the lock", component, path)
+		}
+
+		r.packageLogger(component).V(logging.LevelDebug).Info("Package not locked, it was already fetched", "path", path)
+
+		return nil
+	}
+
+	// The package doesn't contain any file when the fetch didn't create its directory
+	files := map[string]string{}
+	if r.fSys.Exists(path) {
+		var err error
+		if files, err = lock.Hash(r.fSys, path); err != nil {
+			return err
+		}
+	}
+
+	pkg := lock.Package{
+		Component: component,
+		RepoURI:   pkgOpts.RepoURI,
+		Directory: pkgOpts.Path,
+		Ref:       r.repoRef,
+		Commit:    kpt.LockedCommit(r.fSys, path),
+		Files:     files,
+	}
+
+	r.lockMu.Lock()
+	defer r.lockMu.Unlock()
+
+	if r.locked {
+		locked, _ := r.lock.Get(component)
+
+		return errors.Wrapf(locked.Verify(pkg), "the %s package differs from the %s lock file", component, r.lockFile)
+	}
+
+	r.lock.Set(pkg)
+
+	return lock.Write(r.fSys, r.lockFile, r.lock)
+}
@@ -0,0 +1,111 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/electrocucaracha/nephioadm/internal/app"
+	"github.com/electrocucaracha/nephioadm/internal/lock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const (
+	lockedCommit = "0123456789abcdef0123456789abcdef01234567"
+	lockedFile   = "/opt/nephio/mgmt/nephioadm.lock"
+)
+
+var _ = Describe("Lock file", func() {
+	var provider *app.NephioProvider
+	var fSys filesys.FileSystem
+	var upstream map[string]string
+
+	join := func(basePath string, locked bool) error {
+		return provider.Join(context.Background(), &app.NephioRunnerOptions{
+			BasePath:      basePath,
+			NephioRepoURI: app.DefaultNephioRepoURI,
+			NephioRepoRef: "v1.0.1",
+			LockFile:      lockedFile,
+			Locked:        locked,
+			SkipVerify:    true,
+		})
+	}
+
+	BeforeEach(func() {
+		fSys = filesys.MakeFsInMemory()
+		upstream = map[string]string{"deployment.yaml": "kind: Deployment\n"}
+
+		client := NewMockClient()
+		client.OnPkgGet = func(path, source string) {
+			// kpt writes the requested ref into the Kptfile, so it differs when the locked commit is fetched
+			_, ref, _ := strings.Cut(source, "@")
+			Expect(fSys.WriteFile(filepath.Join(path, "Kptfile"), []byte("kind: Kptfile\nupstream:\n  git:\n    ref: "+
+				ref+"\nupstreamLock:\n  git:\n    ref: "+ref+"\n    commit: "+lockedCommit+"\n"))).To(Succeed())
+
+			for name, content := range upstream {
+				Expect(fSys.WriteFile(filepath.Join(path, name), []byte(content))).To(Succeed())
+			}
+		}
+		provider = app.NewProvider(client, fSys, NewMockCluster().newCluster)
+
+		Expect(join("/opt/nephio/mgmt", false)).To(Succeed())
+	})
+
+	It("should record the resolved revision and checksums of the fetched packages", func() {
+		lockFile, err := lock.Read(fSys, lockedFile)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lockFile.Packages).To(HaveLen(1))
+		Expect(lockFile.Packages[0].Component).To(Equal(app.ComponentConfigSync))
+		Expect(lockFile.Packages[0].RepoURI).To(Equal(app.DefaultNephioRepoURI))
+		Expect(lockFile.Packages[0].Directory).To(Equal("nephio-configsync"))
+		Expect(lockFile.Packages[0].Ref).To(Equal("v1.0.1"))
+		Expect(lockFile.Packages[0].Commit).To(Equal(lockedCommit))
+		Expect(lockFile.Packages[0].Files).To(HaveKey("deployment.yaml"))
+	})
+
+	It("should install the packages matching the lock file", func() {
+		Expect(join("/opt/nephio/edge", true)).To(Succeed())
+		Expect(fSys.ReadFile("/opt/nephio/edge/configsync/Kptfile")).To(ContainSubstring("ref: " + lockedCommit))
+	})
+
+	DescribeTable("locked installation refusal", func(setup func(), expected string) {
+		setup()
+
+		Expect(join("/opt/nephio/edge", true)).To(MatchError(ContainSubstring(expected)))
+	},
+		Entry("when the package content differs", func() {
+			upstream["deployment.yaml"] = "kind: Deployment\nspec: {}\n"
+		}, "the deployment.yaml file checksum doesn't match the locked one"),
+		Entry("when the package has additional files", func() {
+			upstream["service.yaml"] = "kind: Service\n"
+		}, "the service.yaml file isn't locked"),
+		Entry("when the package was already fetched", func() {
+			Expect(fSys.MkdirAll("/opt/nephio/edge/configsync")).To(Succeed())
+		}, "its content can't be verified against the lock"),
+		Entry("when the lock file doesn't exist", func() {
+			Expect(fSys.RemoveAll(lockedFile)).To(Succeed())
+		}, "lock file is required by the locked installation"),
+		Entry("when the package isn't locked", func() {
+			Expect(lock.Write(fSys, lockedFile, &lock.File{})).To(Succeed())
+		}, "the configsync package isn't locked"),
+	)
+})
@@ -17,50 +17,292 @@ limitations under the License.
 package app
 
 import (
-	"os"
+	"context"
 
+	"github.com/electrocucaracha/nephioadm/internal/bundle"
+	"github.com/electrocucaracha/nephioadm/internal/cache"
+	"github.com/electrocucaracha/nephioadm/internal/k8s"
 	"github.com/electrocucaracha/nephioadm/internal/kpt"
-	"k8s.io/apimachinery/pkg/runtime"
+	"github.com/electrocucaracha/nephioadm/internal/logging"
+	"github.com/electrocucaracha/nephioadm/internal/tracing"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
 
 type Provider interface {
-	Init(*NephioRunnerOptions) error
-	Join(*NephioRunnerOptions) error
+	Init(context.Context, *NephioRunnerOptions) error
+	Join(context.Context, *NephioRunnerOptions) error
+	Reset(context.Context, *ResetOptions) error
+	Status(context.Context, *StatusOptions) ([]ComponentStatus, error)
+	Apply(context.Context, *FleetOptions) ([]ClusterResult, error)
+	ListClusters(context.Context, *k8s.ClusterOptions) ([]WorkloadCluster, error)
+	Verify(context.Context, *VerifyOptions) ([]CheckResult, error)
+	SupportBundle(context.Context, *SupportBundleOptions) error
+	ListCache(context.Context, *CacheOptions) ([]cache.Entry, error)
+	PruneCache(context.Context, *CacheOptions) ([]cache.Entry, error)
+	CreateBundle(context.Context, *BundleOptions) (*bundle.Manifest, error)
+	ListImages(context.Context, *ImagesOptions) ([]ImageInfo, error)
+	InstallState(context.Context, *k8s.ClusterOptions) (*InstallState, error)
 }
 
 type NephioProvider struct {
-	client        kpt.Client
-	readResource  func(func(string) ([]byte, error), string, interface{}) error
-	writeResource func(func(string) (*os.File, error), string, runtime.Object) error
+	client     kpt.Client
+	fSys       filesys.FileSystem
+	newCluster func(*k8s.ClusterOptions) (k8s.ClusterClient, error)
+	resolve    cache.Resolver
+	log        logr.Logger
 }
 
 var _ Provider = (*NephioProvider)(nil)
 
-func NewProvider(client kpt.Client,
-	readResourceFunc func(func(string) ([]byte, error), string, interface{}) error,
-	writeResourceFunc func(func(string) (*os.File, error), string, runtime.Object) error,
+func NewProvider(client kpt.Client, fSys filesys.FileSystem,
+	newClusterFunc func(*k8s.ClusterOptions) (k8s.ClusterClient, error),
 ) *NephioProvider {
 	return &NephioProvider{
-		client:        client,
-		readResource:  readResourceFunc,
-		writeResource: writeResourceFunc,
+		client:     client,
+		fSys:       fSys,
+		newCluster: newClusterFunc,
+		resolve:    cache.LsRemote,
+		log:        logging.Default(),
 	}
 }
 
-func (p NephioProvider) Init(opts *NephioRunnerOptions) error {
-	runner := NewRunner(p.client, p.readResource, p.writeResource, opts)
-	runner.InstallSystem()
+// SetLogger sets the logger used to report the provider operations.
+func (p *NephioProvider) SetLogger(logger logr.Logger) {
+	p.log = logger
+}
 
-	if err := runner.InstallWebUI(); err != nil {
-		return err
+// SetResolver sets the resolver of the package commits used to address the package cache.
+func (p *NephioProvider) SetResolver(resolve cache.Resolver) {
+	p.resolve = resolve
+}
+
+// newRunner creates a runner which waits for the installed package resources on the target cluster.
+func (p NephioProvider) newRunner(ctx context.Context, opts *NephioRunnerOptions, logger logr.Logger,
+	phase *PhaseResult,
+) (*NephioRunner, error) {
+	cluster, err := p.newCluster(&opts.Cluster)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to the cluster")
+	}
+
+	runner, err := p.newPackageRunner(ctx, opts, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	runner.cluster = cluster
+	runner.report = phase
+
+	runner.checkpoint, err = loadCheckpoint(p.fSys, runner.basePath, phase.Name,
+		&phaseCheckpoint{RepoURI: opts.NephioRepoURI, Ref: opts.NephioRepoRef}, opts.Resume)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return runner, nil
 }
 
-func (p NephioProvider) Join(opts *NephioRunnerOptions) error {
-	runner := NewRunner(p.client, p.readResource, p.writeResource, opts)
-	runner.InstallConfigSync()
+// newPackageRunner creates a runner which prepares the packages from the sources of the options,
+// without accessing any cluster.
+func (p NephioProvider) newPackageRunner(ctx context.Context, opts *NephioRunnerOptions,
+	logger logr.Logger,
+) (*NephioRunner, error) {
+	runner := NewRunner(p.client, p.fSys, opts)
+	runner.log = logger
+	runner.ctx = ctx
+
+	if err := runner.loadLock(opts.Locked); err != nil {
+		return nil, err
+	}
+
+	if len(opts.CacheDir) != 0 {
+		runner.cache = cache.New(p.fSys, opts.CacheDir, p.resolve)
+	}
+
+	if len(opts.Bundle) != 0 {
+		manifest, err := bundle.Read(p.fSys, opts.Bundle)
+		if err != nil {
+			return nil, err
+		}
+
+		logger.Info("Using the packages of the bundle", "bundle", opts.Bundle,
+			"repo", manifest.Repository, "ref", manifest.Ref)
+		runner.bundle = opts.Bundle
+		runner.bundleManifest = manifest
+	}
+
+	return runner, nil
+}
+
+// runPhase runs the phase provided within its span and writes its results into the run reports requested.
+func (p NephioProvider) runPhase(ctx context.Context, opts *NephioRunnerOptions, name string,
+	run func(context.Context, logr.Logger, *PhaseResult) error,
+) error {
+	logger := p.log
+
+	if opts.WriteLogs {
+		logFile, err := createLogFile(p.fSys, opts.BasePath, name)
+		if err != nil {
+			return err
+		}
+		defer logFile.Close()
+
+		logger = logging.Tee(logger, logFile)
+	}
+
+	clusterContext := opts.Cluster.CurrentContext()
+	logger = logger.WithValues("phase", name, "cluster", clusterContext)
+	phase := newPhaseResult(name)
+
+	ctx, span := tracing.Tracer(opts.TracerProvider).Start(ctx, "nephioadm "+name, trace.WithAttributes(
+		tracing.PhaseKey.String(name),
+		tracing.ClusterKey.String(clusterContext),
+		tracing.PackageRepoKey.String(opts.NephioRepoURI),
+	))
+	defer span.End()
+
+	err := run(ctx, logger, phase)
+	phase.finish(err)
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	if reportErr := writeReports(p.fSys, opts, phase); reportErr != nil {
+		if err == nil {
+			return reportErr
+		}
+
+		logger.Error(reportErr, "Failed to write the run reports")
+	}
+
+	return err
+}
+
+func (p NephioProvider) Init(ctx context.Context, opts *NephioRunnerOptions) error {
+	return p.runPhase(ctx, opts, PhaseInit, func(ctx context.Context, logger logr.Logger, phase *PhaseResult) error {
+		logger.Info("Installing the Nephio control plane")
+
+		runner, err := p.newRunner(ctx, opts, logger, phase)
+		if err != nil {
+			return err
+		}
+
+		if err := runner.Install(phaseComponents[PhaseInit]...); err != nil {
+			return err
+		}
 
-	return nil
+		if err := p.verifyInstallation(ctx, opts, logger, phase); err != nil {
+			return err
+		}
+
+		return phase.runStep(StepRecord, "", func() error {
+			return runner.recordState(PhaseInit)
+		})
+	})
+}
+
+func (p NephioProvider) Join(ctx context.Context, opts *NephioRunnerOptions) error {
+	if opts.RegisterCluster() && len(opts.ClusterName) == 0 {
+		return errors.New("a cluster name is required to register the workload cluster")
+	}
+
+	return p.runPhase(ctx, opts, PhaseJoin, func(ctx context.Context, logger logr.Logger, phase *PhaseResult) error {
+		logger.Info("Joining the cluster to the Nephio control plane")
+
+		runner, err := p.newRunner(ctx, opts, logger, phase)
+		if err != nil {
+			return err
+		}
+
+		if err := runner.Install(phaseComponents[PhaseJoin]...); err != nil {
+			return err
+		}
+
+		if err := p.verifyInstallation(ctx, opts, logger, phase); err != nil {
+			return err
+		}
+
+		if err := phase.runStep(StepRecord, "", func() error {
+			return runner.recordState(PhaseJoin)
+		}); err != nil {
+			return err
+		}
+
+		if !opts.RegisterCluster() {
+			return nil
+		}
+
+		logger.Info("Registering the workload cluster", "name", opts.ClusterName, "mgmtContext", opts.MgmtContext)
+
+		return phase.runStep(StepRegister, "", func() error {
+			mgmt, err := p.newCluster(&k8s.ClusterOptions{Kubeconfig: opts.MgmtKubeconfig, Context: opts.MgmtContext})
+			if err != nil {
+				return errors.Wrap(err, "failed to connect to the management cluster")
+			}
+
+			return registerWorkloadCluster(ctx, mgmt, newWorkloadCluster(opts))
+		})
+	})
+}
+
+// ListClusters retrieves the workload clusters registered in the management cluster.
+func (p NephioProvider) ListClusters(ctx context.Context, opts *k8s.ClusterOptions) ([]WorkloadCluster, error) {
+	mgmt, err := p.newCluster(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to the management cluster")
+	}
+
+	return listWorkloadClusters(ctx, mgmt)
+}
+
+// Verify runs the readiness checks of the components installed during the phase provided.
+func (p NephioProvider) Verify(ctx context.Context, opts *VerifyOptions) ([]CheckResult, error) {
+	cluster, err := p.newCluster(&opts.Cluster)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to the cluster")
+	}
+
+	return verify(ctx, cluster, opts.Phase, opts.Timeout)
+}
+
+// SupportBundle writes a tarball with the diagnostics of the cluster and the local packages.
+func (p NephioProvider) SupportBundle(ctx context.Context, opts *SupportBundleOptions) error {
+	cluster, err := p.newCluster(&opts.Cluster)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to the cluster")
+	}
+
+	file, err := p.fSys.Create(opts.Output)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the %s support bundle", opts.Output)
+	}
+	defer file.Close()
+
+	return collectSupportBundle(ctx, cluster, p.fSys, opts, file)
+}
+
+func (p NephioProvider) verifyInstallation(ctx context.Context, opts *NephioRunnerOptions, logger logr.Logger,
+	phase *PhaseResult,
+) error {
+	if opts.SkipVerify {
+		phase.addStep(StepResult{Name: StepVerify, Outcome: OutcomeSkipped})
+
+		return nil
+	}
+
+	return phase.runStep(StepVerify, "", func() error {
+		results, err := p.Verify(ctx, &VerifyOptions{Cluster: opts.Cluster, Phase: phase.Name, Timeout: opts.VerifyTimeout})
+		for _, result := range results {
+			logger.Info("Component verified", "component", result.Name, "ready", result.Ready,
+				"duration", result.Duration.String(), "message", result.Message)
+		}
+
+		return err
+	})
 }
@@ -17,72 +17,256 @@ limitations under the License.
 package app_test
 
 import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/electrocucaracha/nephioadm/internal/app"
+	"github.com/electrocucaracha/nephioadm/internal/k8s"
 	"github.com/electrocucaracha/nephioadm/internal/kpt"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
 )
 
+type mockCluster struct {
+	Resources map[string]*unstructured.Unstructured
+	NotReady  bool
+
+	// Objects are indexed by resource, namespace and name
+	Objects map[string]*unstructured.Unstructured
+	// Conflicts is the number of updates with a resource version which fail with a conflict
+	Conflicts int
+
+	mu sync.Mutex
+}
+
+func NewMockCluster() *mockCluster {
+	return &mockCluster{
+		Resources: map[string]*unstructured.Unstructured{},
+		Objects:   map[string]*unstructured.Unstructured{},
+	}
+}
+
+func (m *mockCluster) AddObject(resource string, object *unstructured.Unstructured) {
+	m.Objects[resource+"/"+object.GetNamespace()+"/"+object.GetName()] = object
+}
+
+// RecordState stores the installation state of the components provided.
+func (m *mockCluster) RecordState(components ...app.ComponentState) {
+	data, err := yaml.Marshal(&app.InstallState{Components: components})
+	Expect(err).NotTo(HaveOccurred())
+
+	Expect(m.ApplyResource(context.Background(), schema.GroupVersionResource{Version: "v1", Resource: "configmaps"},
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"kind":     "ConfigMap",
+			"metadata": map[string]interface{}{"name": app.StateConfigMap, "namespace": app.StateNamespace},
+			"data":     map[string]interface{}{"state.yaml": string(data)},
+		}})).To(Succeed())
+}
+
+func (m *mockCluster) ApplyResource(ctx context.Context, gvr schema.GroupVersionResource,
+	resource *unstructured.Unstructured,
+) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := resource.GetKind() + "/" + resource.GetName()
+	version := 0
+
+	if current, ok := m.Resources[key]; ok {
+		version, _ = strconv.Atoi(current.GetResourceVersion())
+		if len(resource.GetResourceVersion()) != 0 && resource.GetResourceVersion() != current.GetResourceVersion() {
+			return apierrors.NewConflict(gvr.GroupResource(), resource.GetName(), errors.New("stale version"))
+		}
+	}
+
+	if len(resource.GetResourceVersion()) != 0 && m.Conflicts > 0 {
+		m.Conflicts--
+
+		return apierrors.NewConflict(gvr.GroupResource(), resource.GetName(), errors.New("concurrent update"))
+	}
+
+	stored := resource.DeepCopy()
+	stored.SetResourceVersion(strconv.Itoa(version + 1))
+	m.Resources[key] = stored
+
+	return nil
+}
+
+func (m *mockCluster) GetResource(ctx context.Context, gvr schema.GroupVersionResource,
+	namespace, name string,
+) (*unstructured.Unstructured, error) {
+	if object, ok := m.Objects[gvr.Resource+"/"+namespace+"/"+name]; ok {
+		return object, nil
+	}
+
+	if gvr.Resource == "configmaps" {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		if resource, ok := m.Resources["ConfigMap/"+name]; ok && resource.GetNamespace() == namespace {
+			return resource.DeepCopy(), nil
+		}
+
+		return nil, apierrors.NewNotFound(gvr.GroupResource(), name)
+	}
+
+	status := "True"
+	if m.NotReady {
+		status = "False"
+	}
+
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": name, "namespace": namespace},
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Available", "status": status, "message": "test"},
+				map[string]interface{}{"type": "Established", "status": status, "message": "test"},
+			},
+		},
+	}}, nil
+}
+
+func (m *mockCluster) ListResources(ctx context.Context, gvr schema.GroupVersionResource,
+	namespace, labelSelector string,
+) ([]unstructured.Unstructured, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	items := []unstructured.Unstructured{}
+
+	for key, object := range m.Objects {
+		if strings.HasPrefix(key, gvr.Resource+"/"+namespace+"/") {
+			items = append(items, *object)
+		}
+	}
+
+	for _, resource := range m.Resources {
+		if resource.GetKind() == "ConfigMap" && resource.GetNamespace() == namespace {
+			items = append(items, *resource)
+		}
+	}
+
+	return items, nil
+}
+
+func (m *mockCluster) ResourceFor(gk schema.GroupKind) (schema.GroupVersionResource, error) {
+	return schema.GroupVersionResource{
+		Group: gk.Group, Version: "v1", Resource: strings.ToLower(gk.Kind) + "s",
+	}, nil
+}
+
+func (m *mockCluster) GetPodLogs(ctx context.Context, namespace, pod, container string) ([]byte, error) {
+	return []byte(container + " logs"), nil
+}
+
+func (m *mockCluster) newCluster(opts *k8s.ClusterOptions) (k8s.ClusterClient, error) {
+	return m, nil
+}
+
 type mockClient struct {
-	SetLocalPathCallerCount int
-	PkgGetCallerCount       int
-	PkgTreeCallerCount      int
-	PkgDiffCallerCount      int
-	FnRenderCallerCount     int
-	FnEvalCallerCount       int
-	LiveInitCallerCount     int
-	LiveApplyCallerCount    int
-	LiveStatusCallerCount   int
+	PkgGetCallerCount      int
+	PkgTreeCallerCount     int
+	PkgDiffCallerCount     int
+	FnRenderCallerCount    int
+	FnEvalCallerCount      int
+	LiveInitCallerCount    int
+	LiveApplyCallerCount   int
+	LiveDestroyCallerCount int
+
+	LiveApplyErr error
+
+	// Commands records the kpt commands and the package path they run on
+	Commands []string
+	// FnEvalImages records the images of the evaluated kpt functions
+	FnEvalImages []string
+	// FnEvalValues records the values put by the evaluated kpt functions
+	FnEvalValues []string
+	// OnCommand is called before recording every kpt command
+	OnCommand func(command string)
+	// OnPkgGet is called with the package path and source of every fetch
+	OnPkgGet func(path, source string)
+	// ReconcileTimeout records the reconcile timeout of the last apply
+	ReconcileTimeout time.Duration
+
+	mu sync.Mutex
 }
 
 func NewMockClient() *mockClient {
-	return &mockClient{
-		SetLocalPathCallerCount: 0,
-		PkgGetCallerCount:       0,
-		PkgTreeCallerCount:      0,
-		PkgDiffCallerCount:      0,
-		FnRenderCallerCount:     0,
-		FnEvalCallerCount:       0,
-		LiveInitCallerCount:     0,
-		LiveApplyCallerCount:    0,
-		LiveStatusCallerCount:   0,
-	}
+	return &mockClient{}
 }
 
-func (m *mockClient) SetLocalPath(localPath string) {
-	m.SetLocalPathCallerCount += 1
+// observe records and notifies the execution of the kpt command provided.
+func (m *mockClient) observe(opts *kpt.CommandOptions, callerCount *int, err error, args ...string) error {
+	command := strings.Join(append(args[:2:2], opts.Path), " ")
+	if m.OnCommand != nil {
+		m.OnCommand(command)
+	}
+
+	m.mu.Lock()
+	*callerCount++
+	m.Commands = append(m.Commands, command)
+	m.mu.Unlock()
+
+	if opts.Observer != nil {
+		opts.Observer(kpt.CommandResult{Args: append(args, opts.Path), Err: err})
+	}
+
+	return err
 }
 
-func (m *mockClient) PkgGet(pkg *kpt.Package) {
-	m.PkgGetCallerCount++
+func (m *mockClient) PkgGet(ctx context.Context, opts *kpt.CommandOptions, pkg *kpt.Package) error {
+	if m.OnPkgGet != nil {
+		m.OnPkgGet(opts.Path, pkg.String())
+	}
+
+	return m.observe(opts, &m.PkgGetCallerCount, nil, "pkg", "get", pkg.String())
 }
 
-func (m *mockClient) PkgTree() {
-	m.PkgTreeCallerCount += 1
+func (m *mockClient) PkgTree(ctx context.Context, opts *kpt.CommandOptions) error {
+	return m.observe(opts, &m.PkgTreeCallerCount, nil, "pkg", "tree")
 }
 
-func (m *mockClient) PkgDiff() {
-	m.PkgDiffCallerCount += 1
+func (m *mockClient) PkgDiff(ctx context.Context, opts *kpt.CommandOptions) error {
+	return m.observe(opts, &m.PkgDiffCallerCount, nil, "pkg", "diff")
 }
 
-func (m *mockClient) FnRender() {
-	m.FnRenderCallerCount += 1
+func (m *mockClient) FnRender(ctx context.Context, opts *kpt.CommandOptions) error {
+	return m.observe(opts, &m.FnRenderCallerCount, nil, "fn", "render")
 }
 
-func (m *mockClient) FnEval(image, byPath, byValueRegex, putValue string) {
-	m.FnEvalCallerCount += 1
+func (m *mockClient) FnEval(ctx context.Context, opts *kpt.CommandOptions,
+	image, byPath, byValueRegex, putValue string,
+) error {
+	m.mu.Lock()
+	m.FnEvalImages = append(m.FnEvalImages, image)
+	m.FnEvalValues = append(m.FnEvalValues, putValue)
+	m.mu.Unlock()
+
+	return m.observe(opts, &m.FnEvalCallerCount, nil, "fn", "eval", "--image", image)
 }
 
-func (m *mockClient) LiveInit() {
-	m.LiveInitCallerCount += 1
+func (m *mockClient) LiveInit(ctx context.Context, opts *kpt.CommandOptions) error {
+	return m.observe(opts, &m.LiveInitCallerCount, nil, "live", "init")
 }
 
-func (m *mockClient) LiveApply() {
-	m.LiveApplyCallerCount += 1
+func (m *mockClient) LiveApply(ctx context.Context, opts *kpt.CommandOptions) error {
+	m.mu.Lock()
+	m.ReconcileTimeout = opts.ReconcileTimeout
+	m.mu.Unlock()
+
+	return m.observe(opts, &m.LiveApplyCallerCount, m.LiveApplyErr, "live", "apply")
 }
 
-func (m *mockClient) LiveStatus() {
-	m.LiveStatusCallerCount += 1
+func (m *mockClient) LiveDestroy(ctx context.Context, opts *kpt.CommandOptions) error {
+	return m.observe(opts, &m.LiveDestroyCallerCount, nil, "live", "destroy")
 }
 
 func NewNephioRunnerOptions(debug bool, args ...string) *app.NephioRunnerOptions {
@@ -113,7 +297,6 @@ func NewNephioRunnerOptions(debug bool, args ...string) *app.NephioRunnerOptions
 }
 
 func (c *mockClient) checkCallerCountsFromProvider(debug bool, expected, expectedEvalCalls int) {
-	Expect(c.SetLocalPathCallerCount).Should(Equal(expected))
 	Expect(c.PkgGetCallerCount).Should(Equal(expected))
 	Expect(c.FnRenderCallerCount).Should(Equal(expected))
 	Expect(c.FnEvalCallerCount).Should(Equal(expectedEvalCalls))
@@ -123,25 +306,27 @@ func (c *mockClient) checkCallerCountsFromProvider(debug bool, expected, expecte
 	if debug {
 		Expect(c.PkgTreeCallerCount).Should(Equal(expected))
 		Expect(c.PkgDiffCallerCount).Should(Equal(expected))
-		Expect(c.LiveStatusCallerCount).Should(Equal(expected))
 	} else {
 		Expect(c.PkgTreeCallerCount).Should(Equal(0))
 		Expect(c.PkgDiffCallerCount).Should(Equal(0))
-		Expect(c.LiveStatusCallerCount).Should(Equal(0))
 	}
 }
 
 var _ = Describe("Provider Service", func() {
 	var provider app.NephioProvider
 	var client *mockClient
+	var cluster *mockCluster
 
 	BeforeEach(func() {
 		client = NewMockClient()
-		provider = *app.NewProvider(client, fakeReadResourceFromFile, fakeWriteResourceToFile)
+		cluster = NewMockCluster()
+		fSys := newFakeFileSystem()
+		writePackageFiles(fSys, "/test")
+		provider = *app.NewProvider(client, fSys, cluster.newCluster)
 	})
 
 	DescribeTable("initialization execution process", func(debug bool, args ...string) {
-		err := provider.Init(NewNephioRunnerOptions(debug, args...))
+		err := provider.Init(context.Background(), NewNephioRunnerOptions(debug, args...))
 
 		Expect(err).NotTo(HaveOccurred())
 		client.checkCallerCountsFromProvider(debug, 2, 0)
@@ -163,7 +348,7 @@ var _ = Describe("Provider Service", func() {
 	)
 
 	DescribeTable("join execution process", func(debug bool, args ...string) {
-		err := provider.Join(NewNephioRunnerOptions(debug, args...))
+		err := provider.Join(context.Background(), NewNephioRunnerOptions(debug, args...))
 
 		Expect(err).NotTo(HaveOccurred())
 		client.checkCallerCountsFromProvider(debug, 1, 1)
@@ -177,4 +362,107 @@ var _ = Describe("Provider Service", func() {
 			"http://gitea/nephio-packages/"),
 		Entry("when the no options are provided and debug is disable", false),
 	)
+
+	Describe("workload cluster registration", func() {
+		var opts *app.NephioRunnerOptions
+
+		BeforeEach(func() {
+			opts = NewNephioRunnerOptions(false, "/test/", "http://gitea/nephio-internal/packages.git",
+				"http://gitea/nephio-packages/")
+			opts.MgmtContext = "kind-nephio"
+			opts.ClusterName = "regional"
+			opts.ClusterRegion = "us-west1"
+			opts.ClusterLabels = map[string]string{"nephio.org/site-type": "edge"}
+		})
+
+		It("should record the cluster and its deployment repository", func() {
+			Expect(provider.Join(context.Background(), opts)).To(Succeed())
+			Expect(cluster.Resources).To(HaveKey("Repository/regional"))
+			Expect(cluster.Resources).To(HaveKey("ConfigMap/regional"))
+
+			repo, _, _ := unstructured.NestedString(cluster.Resources["Repository/regional"].Object,
+				"spec", "git", "repo")
+			Expect(repo).To(Equal("http://gitea/nephio-packages/regional"))
+
+			clusters, err := provider.ListClusters(context.Background(), &k8s.ClusterOptions{Context: "kind-nephio"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clusters).To(Equal([]app.WorkloadCluster{{
+				Name:   "regional",
+				Region: "us-west1",
+				Repo:   "http://gitea/nephio-packages/regional",
+				Labels: map[string]string{"nephio.org/site-type": "edge"},
+			}}))
+		})
+
+		It("should fail when the cluster name is not provided", func() {
+			opts.ClusterName = ""
+
+			Expect(provider.Join(context.Background(), opts)).NotTo(Succeed())
+			Expect(client.PkgGetCallerCount).Should(Equal(0))
+		})
+
+		It("should skip the registration when no management cluster is provided", func() {
+			opts.MgmtContext = ""
+
+			Expect(provider.Join(context.Background(), opts)).To(Succeed())
+			Expect(cluster.Resources).NotTo(HaveKey("Repository/regional"))
+			Expect(cluster.Resources).NotTo(HaveKey("ConfigMap/regional"))
+		})
+	})
+
+	It("should rewrite the images to be pulled from the mirror", func() {
+		fSys := newFakeFileSystem()
+		Expect(fSys.WriteFile("/opt/nephio/configsync/Kptfile", []byte(`apiVersion: kpt.dev/v1
+kind: Kptfile
+metadata:
+  name: configsync
+pipeline:
+  mutators:
+  - image: gcr.io/kpt-fn/set-namespace:v0.4.1
+`))).To(Succeed())
+		Expect(fSys.WriteFile("/opt/nephio/configsync/reconciler-manager.yaml", []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: reconciler-manager
+spec:
+  template:
+    spec:
+      containers:
+      - name: reconciler-manager
+        image: gcr.io/config-management-release/reconciler-manager:v1.14.2
+`))).To(Succeed())
+		provider = *app.NewProvider(client, fSys, cluster.newCluster)
+
+		Expect(provider.Join(context.Background(), &app.NephioRunnerOptions{
+			SkipVerify: true,
+			ImageMirror: k8s.ImageMirror{
+				Registry: "registry.local:5000",
+				Rules:    []k8s.ImageRule{{From: "gcr.io/kpt-fn", To: "registry.local:5000/kpt-functions"}},
+			},
+		})).To(Succeed())
+
+		Expect(client.FnEvalImages).To(Equal([]string{"registry.local:5000/kpt-functions/search-replace:v0.2"}))
+		Expect(fSys.ReadFile("/opt/nephio/configsync/reconciler-manager.yaml")).To(ContainSubstring(
+			"image: registry.local:5000/config-management-release/reconciler-manager:v1.14.2"))
+		Expect(fSys.ReadFile("/opt/nephio/configsync/Kptfile")).To(ContainSubstring(
+			"image: registry.local:5000/kpt-functions/set-namespace:v0.4.1"))
+	})
+
+	It("should copy the packages of a local repository", func() {
+		fSys := newFakeFileSystem()
+		Expect(fSys.WriteFile("/src/nephio-packages/nephio-configsync/rootsync.yaml",
+			[]byte("kind: RootSync\n"))).To(Succeed())
+		provider = *app.NewProvider(client, fSys, cluster.newCluster)
+
+		Expect(provider.Join(context.Background(), &app.NephioRunnerOptions{
+			BasePath:      "/opt/nephio/edge",
+			NephioRepoURI: "/src/nephio-packages",
+			SkipVerify:    true,
+		})).To(Succeed())
+
+		Expect(client.PkgGetCallerCount).To(BeZero())
+		Expect(client.LiveApplyCallerCount).To(Equal(1))
+		Expect(fSys.ReadFile("/opt/nephio/edge/configsync/rootsync.yaml")).To(BeEquivalentTo("kind: RootSync\n"))
+		Expect(fSys.Exists("/opt/nephio/edge/configsync/Kptfile")).To(BeTrue())
+	})
 })
@@ -0,0 +1,379 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/electrocucaracha/nephioadm/internal/k8s"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	DefaultReconcileTimeout = 15 * time.Minute
+	reconcileInterval       = 2 * time.Second
+	recentEventsLimit       = 3
+
+	// inventoryIDLabel is the label of the ResourceGroup files with the inventory identifier
+	inventoryIDLabel = "cli-utils.sigs.k8s.io/inventory-id"
+)
+
+var (
+	resourceGroupGVR = schema.GroupVersionResource{Group: "kpt.dev", Version: "v1alpha1", Resource: "resourcegroups"}
+	podGVR           = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	eventGVR         = schema.GroupVersionResource{Version: "v1", Resource: "events"}
+	resourceGroupID  = k8s.ResourceID{
+		GroupVersionKind: schema.GroupVersionKind{Group: "kpt.dev", Kind: "ResourceGroup"},
+	}
+)
+
+// PackageInventory identifies the ResourceGroup where kpt records the objects applied by a package.
+type PackageInventory struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	ID        string `json:"inventoryID,omitempty"`
+}
+
+// InventoryObject is a resource applied by kpt and recorded in the package inventory.
+type InventoryObject struct {
+	schema.GroupKind
+	Namespace string
+	Name      string
+}
+
+func (o InventoryObject) String() string {
+	kind := o.Kind
+	if len(o.Group) != 0 {
+		kind += "." + o.Group
+	}
+
+	if len(o.Namespace) != 0 {
+		return kind + " " + o.Namespace + "/" + o.Name
+	}
+
+	return kind + " " + o.Name
+}
+
+// ObjectStatus reports the kstatus of an inventory object.
+type ObjectStatus struct {
+	Object  InventoryObject
+	Status  status.Status
+	Message string
+	Events  []string
+}
+
+func (s ObjectStatus) done() bool {
+	return s.Status == status.CurrentStatus || s.Status == status.FailedStatus
+}
+
+// packageInventory returns the package inventory, which kpt live init stores in a ResourceGroup
+// file or, on earlier kpt versions, in the Kptfile. It's nil when the package wasn't initialized.
+func packageInventory(fSys filesys.FileSystem, path string) (*PackageInventory, error) {
+	if !fSys.IsDir(path) {
+		return nil, nil
+	}
+
+	pkg, err := k8s.ReadPackage(fSys, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if resourceGroups := pkg.Resources(resourceGroupID); len(resourceGroups) != 0 {
+		return &PackageInventory{
+			Namespace: resourceGroups[0].GetNamespace(),
+			Name:      resourceGroups[0].GetName(),
+			ID:        resourceGroups[0].GetLabels()[inventoryIDLabel],
+		}, nil
+	}
+
+	kptfile := filepath.Join(path, "Kptfile")
+	if !fSys.Exists(kptfile) {
+		return nil, nil
+	}
+
+	data, err := fSys.ReadFile(kptfile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the %s file", kptfile)
+	}
+
+	node, err := yaml.Parse(string(data))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the %s file", kptfile)
+	}
+
+	inventory := &PackageInventory{}
+	inventory.Namespace, _ = node.GetString("inventory.namespace")
+	inventory.Name, _ = node.GetString("inventory.name")
+	inventory.ID, _ = node.GetString("inventory.inventoryID")
+
+	if len(inventory.Name) == 0 {
+		return nil, nil
+	}
+
+	return inventory, nil
+}
+
+// inventoryObjects retrieves the objects recorded in the cluster inventory of the package provided.
+func inventoryObjects(ctx context.Context, cluster k8s.ClusterClient, fSys filesys.FileSystem,
+	path string,
+) ([]InventoryObject, error) {
+	inventory, err := packageInventory(fSys, path)
+	if err != nil || inventory == nil {
+		return nil, err
+	}
+
+	return resourceGroupObjects(ctx, cluster, inventory)
+}
+
+// resourceGroupObjects retrieves the objects recorded in the ResourceGroup of the inventory provided.
+func resourceGroupObjects(ctx context.Context, cluster k8s.ClusterClient,
+	inventory *PackageInventory,
+) ([]InventoryObject, error) {
+	resourceGroup, err := cluster.GetResource(ctx, resourceGroupGVR, inventory.Namespace, inventory.Name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the %s/%s package inventory", inventory.Namespace,
+			inventory.Name)
+	}
+
+	resources, _, _ := unstructured.NestedSlice(resourceGroup.Object, "spec", "resources")
+	objects := make([]InventoryObject, 0, len(resources))
+
+	for _, item := range resources {
+		resource, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		object := InventoryObject{}
+		object.Group, _ = resource["group"].(string)
+		object.Kind, _ = resource["kind"].(string)
+		object.Namespace, _ = resource["namespace"].(string)
+		object.Name, _ = resource["name"].(string)
+		objects = append(objects, object)
+	}
+
+	return objects, nil
+}
+
+func objectStatus(ctx context.Context, cluster k8s.ClusterClient, object InventoryObject) ObjectStatus {
+	result := ObjectStatus{Object: object, Status: status.UnknownStatus}
+
+	gvr, err := cluster.ResourceFor(object.GroupKind)
+	if err != nil {
+		result.Message = err.Error()
+
+		return result
+	}
+
+	resource, err := cluster.GetResource(ctx, gvr, object.Namespace, object.Name)
+	if err != nil {
+		result.Status, result.Message = status.NotFoundStatus, err.Error()
+
+		return result
+	}
+
+	computed, err := status.Compute(resource)
+	if err != nil {
+		result.Message = err.Error()
+
+		return result
+	}
+
+	result.Status, result.Message = computed.Status, computed.Message
+
+	return result
+}
+
+// waitForObjects polls the objects provided until all of them are Current or Failed, or the
+// timeout expires.
+func waitForObjects(ctx context.Context, cluster k8s.ClusterClient, objects []InventoryObject,
+	timeout time.Duration,
+) []ObjectStatus {
+	results := make([]ObjectStatus, len(objects))
+	for i, object := range objects {
+		results[i] = ObjectStatus{Object: object, Status: status.UnknownStatus}
+	}
+
+	_ = wait.PollImmediateWithContext(ctx, reconcileInterval, timeout, func(ctx context.Context) (bool, error) {
+		done := true
+
+		for i := range results {
+			if results[i].done() {
+				continue
+			}
+
+			results[i] = objectStatus(ctx, cluster, objects[i])
+			done = done && results[i].done()
+		}
+
+		return done, nil
+	})
+
+	return results
+}
+
+// recentEvents returns the latest events of the resource provided.
+func recentEvents(ctx context.Context, cluster k8s.ClusterClient, namespace, kind, name string) []string {
+	events, err := cluster.ListResources(ctx, eventGVR, namespace, "")
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	matched := []unstructured.Unstructured{}
+
+	for _, event := range events {
+		involvedKind, _, _ := unstructured.NestedString(event.Object, "involvedObject", "kind")
+		involvedName, _, _ := unstructured.NestedString(event.Object, "involvedObject", "name")
+
+		if involvedKind == kind && involvedName == name {
+			matched = append(matched, event)
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return eventTime(matched[i]) < eventTime(matched[j])
+	})
+
+	if len(matched) > recentEventsLimit {
+		matched = matched[len(matched)-recentEventsLimit:]
+	}
+
+	messages := make([]string, 0, len(matched))
+
+	for _, event := range matched {
+		reason, _, _ := unstructured.NestedString(event.Object, "reason")
+		message, _, _ := unstructured.NestedString(event.Object, "message")
+		messages = append(messages, fmt.Sprintf("%s %s: %s", kind, name, strings.TrimSpace(reason+" "+message)))
+	}
+
+	return messages
+}
+
+func eventTime(event unstructured.Unstructured) string {
+	for _, field := range []string{"lastTimestamp", "eventTime"} {
+		if value, found, _ := unstructured.NestedString(event.Object, field); found {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// stuckObjectEvents collects the recent events of a stuck Pod or Deployment, including the
+// events of the Pods managed by the Deployment.
+func stuckObjectEvents(ctx context.Context, cluster k8s.ClusterClient, object InventoryObject) []string {
+	switch object.GroupKind {
+	case schema.GroupKind{Kind: "Pod"}:
+		return recentEvents(ctx, cluster, object.Namespace, object.Kind, object.Name)
+	case schema.GroupKind{Group: "apps", Kind: "Deployment"}:
+	default:
+		return nil
+	}
+
+	events := recentEvents(ctx, cluster, object.Namespace, object.Kind, object.Name)
+
+	deployment, err := cluster.GetResource(ctx, deploymentGVR, object.Namespace, object.Name)
+	if err != nil {
+		return events
+	}
+
+	matchLabels, _, _ := unstructured.NestedStringMap(deployment.Object, "spec", "selector", "matchLabels")
+	if len(matchLabels) == 0 {
+		return events
+	}
+
+	pods, err := cluster.ListResources(ctx, podGVR, object.Namespace, labels.SelectorFromSet(matchLabels).String())
+	if err != nil {
+		return append(events, err.Error())
+	}
+
+	for _, pod := range pods {
+		events = append(events, recentEvents(ctx, cluster, object.Namespace, "Pod", pod.GetName())...)
+	}
+
+	return events
+}
+
+// printStuckObjects writes a summary table of the objects which aren't reconciled.
+func printStuckObjects(out io.Writer, results []ObjectStatus) error {
+	writer := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(writer, "RESOURCE\tSTATUS\tMESSAGE")
+
+	for _, result := range results {
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", result.Object, result.Status, result.Message)
+
+		for _, event := range result.Events {
+			fmt.Fprintf(writer, "\t\t%s\n", event)
+		}
+	}
+
+	return writer.Flush()
+}
+
+// waitForPackage waits for the objects of the package inventory to be reconciled and reports
+// the stuck ones.
+func waitForPackage(ctx context.Context, cluster k8s.ClusterClient, fSys filesys.FileSystem, path string,
+	timeout time.Duration, out io.Writer,
+) error {
+	objects, err := inventoryObjects(ctx, cluster, fSys, path)
+	if err != nil {
+		return err
+	}
+
+	if timeout == 0 {
+		timeout = DefaultReconcileTimeout
+	}
+
+	stuck := []ObjectStatus{}
+
+	for _, result := range waitForObjects(ctx, cluster, objects, timeout) {
+		if result.Status != status.CurrentStatus {
+			result.Events = stuckObjectEvents(ctx, cluster, result.Object)
+			stuck = append(stuck, result)
+		}
+	}
+
+	if len(stuck) == 0 {
+		return nil
+	}
+
+	if err := printStuckObjects(out, stuck); err != nil {
+		return errors.Wrap(err, "failed to print the stuck resources")
+	}
+
+	names := make([]string, 0, len(stuck))
+	for _, result := range stuck {
+		names = append(names, result.Object.String())
+	}
+
+	return errors.Errorf("%d resource(s) of the %s package aren't reconciled: %s", len(stuck), path,
+		strings.Join(names, ", "))
+}
@@ -0,0 +1,126 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/electrocucaracha/nephioadm/internal/app"
+	"github.com/electrocucaracha/nephioadm/internal/logging"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const systemInventory = `apiVersion: kpt.dev/v1alpha1
+kind: ResourceGroup
+metadata:
+  name: inventory-system
+  namespace: nephio-system
+`
+
+func newDeployment(readyReplicas int64) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name": "package-deployment-controller", "namespace": "nephio-system", "generation": int64(1),
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(1),
+			"selector": map[string]interface{}{
+				"matchLabels": map[string]interface{}{"app": "package-deployment-controller"},
+			},
+		},
+		"status": map[string]interface{}{
+			"observedGeneration": int64(1),
+			"replicas":           int64(1),
+			"updatedReplicas":    int64(1),
+			"readyReplicas":      readyReplicas,
+			"availableReplicas":  readyReplicas,
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Progressing", "status": "True", "reason": "NewReplicaSetAvailable"},
+				map[string]interface{}{"type": "Available", "status": "True"},
+			},
+		},
+	}}
+}
+
+var _ = Describe("Package reconciliation", func() {
+	var provider *app.NephioProvider
+	var cluster *mockCluster
+	var opts *app.NephioRunnerOptions
+
+	BeforeEach(func() {
+		var fSys filesys.FileSystem = newFakeFileSystem()
+		Expect(fSys.WriteFile("/opt/nephio/system/resourcegroup.yaml", []byte(systemInventory))).To(Succeed())
+
+		cluster = NewMockCluster()
+		cluster.AddObject("resourcegroups", &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "inventory-system", "namespace": "nephio-system"},
+			"spec": map[string]interface{}{
+				"resources": []interface{}{
+					map[string]interface{}{
+						"group": "apps", "kind": "Deployment",
+						"namespace": "nephio-system", "name": "package-deployment-controller",
+					},
+				},
+			},
+		}})
+
+		provider = app.NewProvider(NewMockClient(), fSys, cluster.newCluster)
+		opts = &app.NephioRunnerOptions{SkipVerify: true, ReconcileTimeout: 10 * time.Millisecond}
+	})
+
+	It("should succeed when the inventory objects are current", func() {
+		cluster.AddObject("deployments", newDeployment(1))
+
+		Expect(provider.Init(context.Background(), opts)).To(Succeed())
+	})
+
+	It("should report the stuck resources and their events", func() {
+		cluster.AddObject("deployments", newDeployment(0))
+		cluster.AddObject("pods", &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name": "package-deployment-controller-1234", "namespace": "nephio-system",
+			},
+		}})
+		cluster.AddObject("events", &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata":       map[string]interface{}{"name": "event-1", "namespace": "nephio-system"},
+			"involvedObject": map[string]interface{}{"kind": "Pod", "name": "package-deployment-controller-1234"},
+			"reason":         "BackOff",
+			"message":        "Back-off pulling image",
+			"lastTimestamp":  "2023-04-01T00:00:00Z",
+		}})
+
+		var out bytes.Buffer
+		logger, err := logging.NewLogger(&out, logging.FormatJSON, logging.LevelInfo)
+		Expect(err).NotTo(HaveOccurred())
+		provider.SetLogger(logger)
+
+		Expect(provider.Init(context.Background(), opts)).To(MatchError(ContainSubstring(
+			"1 resource(s) of the /opt/nephio/system package aren't reconciled: " +
+				"Deployment.apps nephio-system/package-deployment-controller")))
+		Expect(out.String()).To(MatchRegexp(`"msg":"Deployment.apps nephio-system/package-deployment-controller +` +
+			`InProgress +Available: 0/1","phase":"init","cluster":"","package":"system"`))
+		Expect(out.String()).To(MatchRegexp(`"msg":" +Pod package-deployment-controller-1234: ` +
+			`BackOff Back-off pulling image","phase":"init"`))
+	})
+})
@@ -0,0 +1,213 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const (
+	OutcomePassed  = "passed"
+	OutcomeFailed  = "failed"
+	OutcomeSkipped = "skipped"
+)
+
+// Package steps recorded in the run report.
+const (
+	StepGet       = "get"
+	StepCustomize = "customize"
+	StepRender    = "render"
+	StepEval      = "eval"
+	StepInit      = "init"
+	StepApply     = "apply"
+	StepVerify    = "verify"
+	StepRegister  = "register"
+	StepRecord    = "record"
+)
+
+// StepResult reports the execution of a phase step.
+type StepResult struct {
+	Name     string  `json:"name"`
+	Package  string  `json:"package,omitempty"`
+	Command  string  `json:"command,omitempty"`
+	Duration float64 `json:"durationSeconds"`
+	Outcome  string  `json:"outcome"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// PhaseResult reports the execution of a phase and its steps.
+type PhaseResult struct {
+	Name      string       `json:"name"`
+	StartTime time.Time    `json:"startTime"`
+	Duration  float64      `json:"durationSeconds"`
+	Outcome   string       `json:"outcome"`
+	Error     string       `json:"error,omitempty"`
+	Steps     []StepResult `json:"steps"`
+
+	mu sync.Mutex
+}
+
+func outcome(err error) (string, string) {
+	if err != nil {
+		return OutcomeFailed, err.Error()
+	}
+
+	return OutcomePassed, ""
+}
+
+func newPhaseResult(name string) *PhaseResult {
+	return &PhaseResult{Name: name, StartTime: time.Now(), Steps: []StepResult{}}
+}
+
+// addStep records a step, it does nothing when the phase isn't reported.
+func (p *PhaseResult) addStep(step StepResult) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.Steps = append(p.Steps, step)
+}
+
+// runStep runs the step provided and records its duration and outcome.
+func (p *PhaseResult) runStep(name, pkg string, step func() error) error {
+	start := time.Now()
+	err := step()
+
+	result := StepResult{Name: name, Package: pkg, Duration: time.Since(start).Seconds()}
+	result.Outcome, result.Error = outcome(err)
+	p.addStep(result)
+
+	return err
+}
+
+func (p *PhaseResult) finish(err error) {
+	p.Duration = time.Since(p.StartTime).Seconds()
+	p.Outcome, p.Error = outcome(err)
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct{}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+func junitTime(seconds float64) string {
+	return fmt.Sprintf("%.3f", seconds)
+}
+
+// junit converts the phase into a JUnit test suite where each step is a test case.
+func (p *PhaseResult) junit() junitTestSuite {
+	suite := junitTestSuite{
+		Name:      "nephioadm " + p.Name,
+		Time:      junitTime(p.Duration),
+		Timestamp: p.StartTime.UTC().Format(time.RFC3339),
+	}
+
+	for _, step := range p.Steps {
+		testCase := junitTestCase{
+			Name:      step.Name,
+			ClassName: "nephioadm." + p.Name,
+			Time:      junitTime(step.Duration),
+			SystemOut: step.Command,
+		}
+
+		if len(step.Package) != 0 {
+			testCase.ClassName += "." + step.Package
+		}
+
+		switch step.Outcome {
+		case OutcomeFailed:
+			testCase.Failure = &junitFailure{Message: step.Error, Text: step.Error}
+			suite.Failures++
+		case OutcomeSkipped:
+			testCase.Skipped = &junitSkipped{}
+			suite.Skipped++
+		}
+
+		suite.TestCases = append(suite.TestCases, testCase)
+		suite.Tests++
+	}
+
+	return suite
+}
+
+// writeReports writes the phase results into the JSON report and JUnit files requested.
+func writeReports(fSys filesys.FileSystem, opts *NephioRunnerOptions, phases ...*PhaseResult) error {
+	if len(opts.ReportFile) != 0 {
+		data, err := json.MarshalIndent(map[string]interface{}{"phases": phases}, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal the run report")
+		}
+
+		if err := fSys.WriteFile(opts.ReportFile, append(data, '\n')); err != nil {
+			return errors.Wrapf(err, "failed to write the %s run report", opts.ReportFile)
+		}
+	}
+
+	if len(opts.JUnitFile) != 0 {
+		suites := junitTestSuites{}
+		for _, phase := range phases {
+			suites.TestSuites = append(suites.TestSuites, phase.junit())
+		}
+
+		data, err := xml.MarshalIndent(suites, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal the JUnit report")
+		}
+
+		if err := fSys.WriteFile(opts.JUnitFile, append([]byte(xml.Header), append(data, '\n')...)); err != nil {
+			return errors.Wrapf(err, "failed to write the %s JUnit report", opts.JUnitFile)
+		}
+	}
+
+	return nil
+}
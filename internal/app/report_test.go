@@ -0,0 +1,105 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/electrocucaracha/nephioadm/internal/app"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+type runReport struct {
+	Phases []app.PhaseResult `json:"phases"`
+}
+
+func stepNames(steps []app.StepResult) []string {
+	names := []string{}
+	for _, step := range steps {
+		names = append(names, step.Package+"/"+step.Name)
+	}
+
+	return names
+}
+
+var _ = Describe("Run report", func() {
+	var provider *app.NephioProvider
+	var client *mockClient
+	var fSys filesys.FileSystem
+	var opts *app.NephioRunnerOptions
+
+	BeforeEach(func() {
+		fSys = newFakeFileSystem()
+		client = NewMockClient()
+		provider = app.NewProvider(client, fSys, NewMockCluster().newCluster)
+		opts = &app.NephioRunnerOptions{
+			SkipVerify: true, ReportFile: "/tmp/report.json", JUnitFile: "/tmp/junit.xml",
+		}
+	})
+
+	readReport := func() runReport {
+		data, err := fSys.ReadFile("/tmp/report.json")
+		Expect(err).NotTo(HaveOccurred())
+
+		var report runReport
+		Expect(json.Unmarshal(data, &report)).To(Succeed())
+
+		return report
+	}
+
+	It("should record every package step of the phase", func() {
+		Expect(provider.Init(context.Background(), opts)).To(Succeed())
+
+		report := readReport()
+		Expect(report.Phases).To(HaveLen(1))
+		Expect(report.Phases[0].Name).To(Equal(app.PhaseInit))
+		Expect(report.Phases[0].Outcome).To(Equal(app.OutcomePassed))
+		Expect(stepNames(report.Phases[0].Steps)).To(Equal([]string{
+			"system/get", "system/customize", "system/render", "system/init", "system/apply",
+			"webui/get", "webui/customize", "webui/render", "webui/init", "webui/apply",
+			"/verify", "/record",
+		}))
+		Expect(report.Phases[0].Steps[2].Command).To(Equal("kpt fn render /opt/nephio/system"))
+		Expect(report.Phases[0].Steps[10].Outcome).To(Equal(app.OutcomeSkipped))
+
+		Expect(fSys.ReadFile("/tmp/junit.xml")).To(ContainSubstring(
+			`<testcase name="render" classname="nephioadm.init.system"`))
+	})
+
+	It("should record the failed step and its error", func() {
+		client.LiveApplyErr = errors.New("1 resource(s) failed to be applied")
+
+		Expect(provider.Join(context.Background(), opts)).NotTo(Succeed())
+
+		report := readReport()
+		Expect(report.Phases[0].Outcome).To(Equal(app.OutcomeFailed))
+		Expect(stepNames(report.Phases[0].Steps)).To(Equal([]string{
+			"configsync/get", "configsync/eval", "configsync/customize", "configsync/render",
+			"configsync/init", "configsync/apply",
+		}))
+		Expect(report.Phases[0].Steps[5].Error).To(Equal("1 resource(s) failed to be applied"))
+
+		junit, err := fSys.ReadFile("/tmp/junit.xml")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(junit)).To(ContainSubstring(`failures="1"`))
+		Expect(string(junit)).To(ContainSubstring(`<failure message="1 resource(s) failed to be applied">`))
+	})
+})
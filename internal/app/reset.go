@@ -0,0 +1,138 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/electrocucaracha/nephioadm/internal/k8s"
+	"github.com/electrocucaracha/nephioadm/internal/kpt"
+	"github.com/pkg/errors"
+)
+
+type ResetOptions struct {
+	Cluster k8s.ClusterOptions
+	// BasePath contains the component packages, the resources of the components missing from the
+	// default one are found with the installation state recorded in the cluster when it isn't provided
+	BasePath string
+	// Phase limits the reset to the components installed on it, all of them are reset when it's empty
+	Phase string
+	// KeepPackages preserves the local packages once their resources are deleted
+	KeepPackages bool
+}
+
+// resetComponents returns the components to reset in the reverse order of their installation.
+func resetComponents(phase string) ([]string, error) {
+	components := allComponents()
+
+	if len(phase) != 0 {
+		phaseList, ok := phaseComponents[phase]
+		if !ok {
+			return nil, errors.Errorf("unknown %q reset phase", phase)
+		}
+
+		components = append([]string{}, phaseList...)
+	}
+
+	for i, j := 0, len(components)-1; i < j; i, j = i+1, j-1 {
+		components[i], components[j] = components[j], components[i]
+	}
+
+	return components, nil
+}
+
+// recordedPackage writes a package with the inventory recorded in the installation state for the
+// component provided, so its resources are deleted without the local package. The path is empty
+// when the component inventory wasn't recorded.
+func (p NephioProvider) recordedPackage(state *InstallState, component string) (string, error) {
+	recorded := state.component(component)
+	if recorded == nil || recorded.Inventory == nil {
+		return "", nil
+	}
+
+	path := filepath.Join(os.TempDir(), "nephioadm", component)
+	inventory := recorded.Inventory
+
+	return path, kpt.WriteInventoryPackage(p.fSys, path, inventory.Namespace, inventory.Name, inventory.ID)
+}
+
+// Reset deletes the resources of the Nephio components from the cluster and removes their local
+// packages from the base path. Without a base path, the resources of the components which aren't
+// stored in the default one are found with the installation state recorded in the cluster.
+func (p NephioProvider) Reset(ctx context.Context, opts *ResetOptions) error {
+	components, err := resetComponents(opts.Phase)
+	if err != nil {
+		return err
+	}
+
+	basePath := opts.BasePath
+	if len(basePath) == 0 {
+		basePath = DefaultBasePath
+	}
+
+	var state *InstallState
+
+	for _, component := range components {
+		if err := ctx.Err(); err != nil {
+			return errors.Wrap(err, "the reset was aborted")
+		}
+
+		path := filepath.Join(basePath, component)
+		stored := p.fSys.IsDir(path)
+
+		if !stored {
+			if len(opts.BasePath) != 0 {
+				continue
+			}
+
+			if state == nil {
+				if state, err = p.recordedState(ctx, &opts.Cluster); err != nil {
+					return err
+				}
+			}
+
+			if path, err = p.recordedPackage(state, component); err != nil {
+				return err
+			}
+
+			if len(path) == 0 {
+				continue
+			}
+		}
+
+		logger := p.log.WithValues("package", component)
+		logger.Info("Deleting package resources")
+
+		if err := p.client.LiveDestroy(ctx, &kpt.CommandOptions{
+			Path: path, Logger: logger, Kubeconfig: opts.Cluster.Kubeconfig, Context: opts.Cluster.Context,
+		}); err != nil {
+			return errors.Wrapf(err, "failed to delete the %s package resources", path)
+		}
+
+		if stored && opts.KeepPackages {
+			continue
+		}
+
+		if err := p.fSys.RemoveAll(path); err != nil {
+			return errors.Wrapf(err, "failed to remove the %s package", path)
+		}
+	}
+
+	return nil
+}
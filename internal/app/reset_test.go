@@ -0,0 +1,114 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/electrocucaracha/nephioadm/internal/app"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+var _ = Describe("Reset", func() {
+	var provider *app.NephioProvider
+	var client *mockClient
+	var fSys filesys.FileSystem
+	var cluster *mockCluster
+
+	BeforeEach(func() {
+		fSys = newFakeFileSystem()
+		for _, component := range []string{app.ComponentSystem, app.ComponentWebUI, app.ComponentConfigSync} {
+			Expect(fSys.MkdirAll("/opt/nephio/" + component)).To(Succeed())
+		}
+
+		client = NewMockClient()
+		cluster = NewMockCluster()
+		provider = app.NewProvider(client, fSys, cluster.newCluster)
+	})
+
+	DescribeTable("reset execution process", func(opts *app.ResetOptions, removed, kept []string) {
+		Expect(provider.Reset(context.Background(), opts)).To(Succeed())
+
+		Expect(client.LiveDestroyCallerCount).To(Equal(len(removed)))
+		for _, component := range removed {
+			Expect(fSys.Exists("/opt/nephio/" + component)).To(Equal(opts.KeepPackages))
+		}
+
+		for _, component := range kept {
+			Expect(fSys.Exists("/opt/nephio/" + component)).To(BeTrue())
+		}
+	},
+		Entry("when all the components are reset", &app.ResetOptions{},
+			[]string{app.ComponentConfigSync, app.ComponentWebUI, app.ComponentSystem}, []string{}),
+		Entry("when the init components are reset", &app.ResetOptions{Phase: app.PhaseInit},
+			[]string{app.ComponentWebUI, app.ComponentSystem}, []string{app.ComponentConfigSync}),
+		Entry("when the packages are kept", &app.ResetOptions{Phase: app.PhaseJoin, KeepPackages: true},
+			[]string{app.ComponentConfigSync}, []string{app.ComponentSystem, app.ComponentWebUI}),
+	)
+
+	Describe("without the local packages", func() {
+		var destroyed []string
+
+		BeforeEach(func() {
+			Expect(fSys.RemoveAll("/opt/nephio/configsync")).To(Succeed())
+			cluster.RecordState(app.ComponentState{
+				Name: app.ComponentConfigSync, Phase: app.PhaseJoin,
+				Inventory: &app.PackageInventory{Namespace: "config-management-system", Name: "inventory", ID: "id"},
+			})
+
+			destroyed = []string{}
+			client.OnCommand = func(command string) {
+				path := strings.TrimPrefix(command, "live destroy ")
+				kptfile, err := fSys.ReadFile(filepath.Join(path, "Kptfile"))
+				Expect(err).NotTo(HaveOccurred())
+				destroyed = append(destroyed, string(kptfile))
+			}
+		})
+
+		It("should delete the resources recorded in the cluster", func() {
+			Expect(provider.Reset(context.Background(), &app.ResetOptions{Phase: app.PhaseJoin})).To(Succeed())
+
+			Expect(destroyed).To(Equal([]string{`apiVersion: kpt.dev/v1
+kind: Kptfile
+metadata:
+  name: configsync
+inventory:
+  namespace: config-management-system
+  name: inventory
+  inventoryID: id
+`}))
+			Expect(fSys.Exists(filepath.Join(os.TempDir(), "nephioadm", app.ComponentConfigSync))).To(BeFalse())
+		})
+
+		It("should only reset the packages of the base path provided", func() {
+			opts := &app.ResetOptions{Phase: app.PhaseJoin, BasePath: "/opt/nephio"}
+			Expect(provider.Reset(context.Background(), opts)).To(Succeed())
+
+			Expect(destroyed).To(BeEmpty())
+		})
+	})
+
+	It("should fail when the phase is unknown", func() {
+		Expect(provider.Reset(context.Background(), &app.ResetOptions{Phase: "unknown"})).To(
+			MatchError(ContainSubstring("unknown \"unknown\" reset phase")))
+	})
+})
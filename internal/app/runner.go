@@ -17,65 +17,212 @@ limitations under the License.
 package app
 
 import (
-	"io/ioutil"
-	"os"
-
+	"context"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/electrocucaracha/nephioadm/internal/bundle"
+	"github.com/electrocucaracha/nephioadm/internal/cache"
+	"github.com/electrocucaracha/nephioadm/internal/k8s"
 	"github.com/electrocucaracha/nephioadm/internal/kpt"
-	"gopkg.in/yaml.v2"
+	"github.com/electrocucaracha/nephioadm/internal/lock"
+	"github.com/electrocucaracha/nephioadm/internal/logging"
+	"github.com/electrocucaracha/nephioadm/internal/source"
+	"github.com/electrocucaracha/nephioadm/internal/tracing"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	v1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
 )
 
 type Runner interface {
-	InstallSystem()
+	Install(...string) error
+	InstallSystem() error
 	InstallWebUI() error
-	InstallConfigSync()
+	InstallConfigSync() error
 }
 
 type NephioRunner struct {
 	kpt.Client
-	basePath          string
-	gitServiceURI     string
-	backendBaseUrl    string
-	webUIClusterType  string
-	packageOptions    kpt.PackageOptions
-	debug             bool
-	readResourceFunc  func(func(string) ([]byte, error), string, interface{}) error
-	writeResourceFunc func(func(string) (*os.File, error), string, runtime.Object) error
+	basePath         string
+	gitServiceURI    string
+	clusterName      string
+	backendBaseUrl   string
+	webUIClusterType string
+	repoURI          string
+	repoRef          string
+	patchesDir       string
+	debug            bool
+	fSys             filesys.FileSystem
+	reconcileTimeout time.Duration
+	parallelism      int
+	retry            kpt.RetryPolicies
+	clusterOptions   k8s.ClusterOptions
+	imageMirror      k8s.ImageMirror
+	log              logr.Logger
+
+	// report records the package steps, when the run report is requested
+	report *PhaseResult
+	// ctx carries the phase span, which is the parent of the kpt command spans
+	ctx    context.Context
+	tracer trace.Tracer
+
+	// cluster is used to wait for the installed package resources, when it's available
+	cluster k8s.ClusterClient
+	// cache stores the fetched packages, when the cache directory is provided
+	cache *cache.Cache
+	// bundle is the verified archive the packages are extracted from, when it's provided
+	bundle         string
+	bundleManifest *bundle.Manifest
+
+	// lock records the resolved revisions of the fetched packages into lockFile, or verifies them
+	// when the installation is locked
+	lock     *lock.File
+	lockFile string
+	locked   bool
+	lockMu   sync.Mutex
+
+	// checkpoint records the completed package steps of the phase, the completed ones are verified
+	// instead of run again when the phase is resumed
+	checkpoint *checkpoint
+	resume     bool
 }
 
 type NephioRunnerOptions struct {
 	BasePath      string
 	NephioRepoURI string
+	// NephioRepoRef is the git reference the packages are fetched from, the default branch is used when
+	// it isn't set
+	NephioRepoRef string
 	GitServiceURI string
 	Debug         bool
 
+	// Cluster where the components are installed, the current context is used by default
+	Cluster k8s.ClusterOptions
+
 	// Optional
 	BackendBaseUrl   string
 	WebUIClusterType string
+	PatchesDir       string
+	SkipVerify       bool
+	VerifyTimeout    time.Duration
+	ReconcileTimeout time.Duration
+	ReportFile       string
+	JUnitFile        string
+
+	// Parallelism limits the package operations run concurrently, they're run sequentially when it isn't set
+	Parallelism int
+	// RetryPolicies are the kpt retry policies of each operation type, the default ones are used when
+	// they aren't provided
+	RetryPolicies kpt.RetryPolicies
+	// CacheDir stores the fetched packages, so they're copied instead of fetched again
+	CacheDir string
+	// ImageMirror rewrites the container and kpt function images to be pulled from private registries
+	ImageMirror k8s.ImageMirror
+	// Bundle is an archive created by CreateBundle, the packages are installed from it instead of
+	// the Nephio repository
+	Bundle string
+	// LockFile is where the resolved revisions of the fetched packages are written to, the
+	// nephioadm.lock file of the base path is used by default
+	LockFile string
+	// Locked installs the packages of the lock file, the ones which differ from it are refused
+	Locked bool
+	// Resume skips the package steps completed by a previous run of the phase, they're verified instead
+	Resume bool
+	// WriteLogs copies the phase logs into files of the logs directory of the base path
+	WriteLogs bool
+	// TracerProvider records the spans of the phase and its kpt commands, they aren't recorded when
+	// it isn't provided
+	TracerProvider trace.TracerProvider
+
+	// Management cluster where the workload cluster is registered during the join process
+	MgmtKubeconfig string
+	MgmtContext    string
+	ClusterName    string
+	ClusterRegion  string
+	ClusterLabels  map[string]string
+}
+
+// RegisterCluster reports whether the workload cluster has to be registered in a management cluster.
+func (o NephioRunnerOptions) RegisterCluster() bool {
+	return len(o.MgmtKubeconfig) != 0 || len(o.MgmtContext) != 0
 }
 
 var _ Runner = (*NephioRunner)(nil)
 
 const (
 	DefaultBasePath      = "/opt/nephio"
+	DefaultNephioRepoURI = "https://github.com/nephio-project/nephio-packages.git"
+	DefaultGitServiceURI = "https://github.com/nephio-test/"
 	DefaultWebUINodePort = 30007
+	DefaultParallelism   = 4
+
+	// searchReplaceImage is the kpt function used to point ConfigSync to the git service
+	searchReplaceImage = "gcr.io/kpt-fn/search-replace:v0.2"
 )
 
-func NewRunner(client kpt.Client,
-	readResourceFunc func(func(string) ([]byte, error), string, interface{}) error,
-	writeResourceFunc func(func(string) (*os.File, error), string, runtime.Object) error,
-	opts *NephioRunnerOptions,
-) *NephioRunner {
+// Nephio components, each of them is installed from a package of the Nephio repository.
+const (
+	ComponentSystem     = "system"
+	ComponentWebUI      = "webui"
+	ComponentConfigSync = "configsync"
+)
+
+// componentPackages maps the components to their package directories of the Nephio repository.
+var componentPackages = map[string]string{
+	ComponentSystem:     "nephio-system",
+	ComponentWebUI:      "nephio-webui",
+	ComponentConfigSync: "nephio-configsync",
+}
+
+// componentDependencies lists the components which have to be reconciled before applying a component.
+var componentDependencies = map[string][]string{
+	ComponentWebUI: {ComponentSystem},
+}
+
+// phaseComponents lists the components installed on each phase, in installation order.
+var phaseComponents = map[string][]string{
+	PhaseInit: {ComponentSystem, ComponentWebUI},
+	PhaseJoin: {ComponentConfigSync},
+}
+
+var (
+	webUIConfigMapID = k8s.ResourceID{
+		GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+		Name:             "nephio-webui-config",
+	}
+	webUIServiceID = k8s.ResourceID{
+		GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "Service"},
+		Name:             "nephio-webui",
+	}
+)
+
+func NewRunner(client kpt.Client, fSys filesys.FileSystem, opts *NephioRunnerOptions) *NephioRunner {
 	r := &NephioRunner{
-		Client:            client,
-		readResourceFunc:  readResourceFunc,
-		writeResourceFunc: writeResourceFunc,
-		gitServiceURI:     opts.GitServiceURI,
-		packageOptions: kpt.PackageOptions{
-			RepoURI: opts.NephioRepoURI,
-		},
-		debug: opts.Debug,
+		Client:           client,
+		fSys:             fSys,
+		gitServiceURI:    opts.GitServiceURI,
+		clusterName:      opts.ClusterName,
+		repoURI:          opts.NephioRepoURI,
+		repoRef:          opts.NephioRepoRef,
+		patchesDir:       opts.PatchesDir,
+		debug:            opts.Debug,
+		reconcileTimeout: opts.ReconcileTimeout,
+		parallelism:      opts.Parallelism,
+		retry:            opts.RetryPolicies,
+		clusterOptions:   opts.Cluster,
+		imageMirror:      opts.ImageMirror,
+		resume:           opts.Resume,
+		lock:             &lock.File{},
+		log:              logging.Default(),
+		tracer:           tracing.Tracer(opts.TracerProvider),
 	}
 
 	r.basePath = DefaultBasePath
@@ -83,6 +230,15 @@ func NewRunner(client kpt.Client,
 		r.basePath = opts.BasePath
 	}
 
+	if r.reconcileTimeout == 0 {
+		r.reconcileTimeout = DefaultReconcileTimeout
+	}
+
+	r.lockFile = filepath.Join(r.basePath, lock.FileName)
+	if len(opts.LockFile) != 0 {
+		r.lockFile = opts.LockFile
+	}
+
 	if len(opts.BackendBaseUrl) != 0 {
 		r.backendBaseUrl = opts.BackendBaseUrl
 	}
@@ -94,127 +250,515 @@ func NewRunner(client kpt.Client,
 	return r
 }
 
-func (r *NephioRunner) getPackage() {
-	pkg := kpt.NewPackage(&r.packageOptions)
-	r.PkgGet(pkg)
+// packagePath returns the local directory of the component package.
+func (r *NephioRunner) packagePath(component string) string {
+	return filepath.Join(r.basePath, component)
+}
+
+func (r *NephioRunner) packageLogger(component string) logr.Logger {
+	return r.log.WithValues("package", component)
+}
 
-	if r.debug {
-		r.PkgTree()
+// phaseContext returns the context of the phase, which is cancelled when the phase is aborted.
+func (r *NephioRunner) phaseContext() context.Context {
+	if r.ctx == nil {
+		return context.Background()
 	}
+
+	return r.ctx
 }
 
-func (r *NephioRunner) installPackage() {
-	r.FnRender()
+// checkpointed reports whether the completion of the step is recorded in the checkpoint. The
+// evaluation is rolled back with the customization transaction it runs in, so it's only
+// completed along with the customize step.
+func checkpointed(name string) bool {
+	return name != StepEval
+}
 
-	if r.debug {
-		r.PkgDiff()
+// step runs a package step, recording its duration, outcome and kpt commands in the run report.
+// The operation receives the options of the kpt commands run on the component package.
+func (r *NephioRunner) step(name, component string, operation func(*kpt.CommandOptions) error) error {
+	commands := []string{}
+	opts := &kpt.CommandOptions{
+		Path:       r.packagePath(component),
+		Logger:     r.packageLogger(component),
+		Retry:      r.retry,
+		Kubeconfig: r.clusterOptions.Kubeconfig,
+		Context:    r.clusterOptions.Context,
+		// kpt and the inventory wait share the same reconcile timeout
+		ReconcileTimeout: r.reconcileTimeout,
+		Observer: func(result kpt.CommandResult) {
+			commands = append(commands, result.String())
+			r.traceCommand(component, result)
+		},
 	}
 
-	r.LiveInit()
-	r.LiveApply()
+	start := time.Now()
+	resumed := false
 
-	if r.debug {
-		r.LiveStatus()
+	err := r.phaseContext().Err()
+
+	switch {
+	case err != nil:
+		err = errors.Wrapf(err, "the %s step of the %s package was aborted", name, component)
+	case r.resume && checkpointed(name) && r.checkpoint.completed(component, name):
+		opts.Logger.Info("Verifying the completed step", "step", name)
+
+		resumed = true
+		err = r.verifyStep(name, opts)
+	default:
+		if err = operation(opts); err == nil && checkpointed(name) {
+			err = r.checkpoint.complete(component, name)
+		}
 	}
+
+	result := StepResult{
+		Name: name, Package: component, Command: strings.Join(commands, "\n"),
+		Duration: time.Since(start).Seconds(),
+	}
+	result.Outcome, result.Error = outcome(err)
+
+	if resumed && err == nil {
+		result.Outcome = OutcomeSkipped
+	}
+	r.report.addStep(result)
+
+	return err
 }
 
-func (r *NephioRunner) InstallSystem() {
-	r.SetLocalPath(r.basePath + "/system")
-	r.packageOptions.Path = "nephio-system"
+// traceCommand records a span of the kpt command executed for the component package.
+func (r *NephioRunner) traceCommand(component string, result kpt.CommandResult) {
+	end := time.Now()
+
+	_, span := r.tracer.Start(r.phaseContext(), result.Name(), trace.WithTimestamp(end.Add(-result.Duration)),
+		trace.WithAttributes(
+			tracing.PackagePathKey.String(r.packagePath(component)),
+			tracing.PackageRepoKey.String(r.repoURI),
+			tracing.PackageNameKey.String(componentPackages[component]),
+			tracing.CommandKey.String(result.String()),
+			tracing.ExitCodeKey.Int(result.ExitCode),
+		))
+
+	if result.Err != nil {
+		span.RecordError(result.Err)
+		span.SetStatus(codes.Error, result.Err.Error())
+	}
 
-	r.getPackage()
-	r.installPackage()
+	span.End(trace.WithTimestamp(end))
 }
 
-func (r *NephioRunner) setBackendBaseUrl(filename, backendBaseUrl string) error {
-	var configMap v1.ConfigMap
-	if err := r.readResourceFunc(ioutil.ReadFile, filename, &configMap); err != nil {
+// debugCmd runs the kpt command provided when the debug mode is enabled, its failures are only reported.
+func (r *NephioRunner) debugCmd(opts *kpt.CommandOptions,
+	command func(context.Context, *kpt.CommandOptions) error,
+) {
+	if !r.debug {
+		return
+	}
+
+	if err := command(r.phaseContext(), opts); err != nil {
+		opts.Logger.Error(err, "Debug command failed")
+	}
+}
+
+func (r *NephioRunner) getPackage(component string) error {
+	return r.step(StepGet, component, func(opts *kpt.CommandOptions) error {
+		pkgOpts, err := r.packageOptions(component)
+		if err != nil {
+			return err
+		}
+
+		opts.Logger.Info("Fetching package", "source", kpt.NewPackage(pkgOpts).String())
+
+		// The package left by an interrupted fetch is discarded, since kpt doesn't fetch into existing directories
+		if r.resume && r.fSys.Exists(opts.Path) {
+			opts.Logger.Info("Removing the partially fetched package", "path", opts.Path)
+
+			if err := r.fSys.RemoveAll(opts.Path); err != nil {
+				return errors.Wrapf(err, "failed to remove the %s partially fetched package", opts.Path)
+			}
+		}
+
+		fetched := !r.fSys.Exists(opts.Path)
+		if err := r.fetchPackage(component, opts, pkgOpts); err != nil {
+			return err
+		}
+
+		if err := r.lockPackage(component, pkgOpts, fetched); err != nil {
+			return err
+		}
+
+		r.debugCmd(opts, r.PkgTree)
+
+		return nil
+	})
+}
+
+// fetchPackage fetches the component package into its local path. The packages of local
+// repositories are copied, and the git ones are fetched through the package cache when it's
+// enabled and the package wasn't fetched yet.
+func (r *NephioRunner) fetchPackage(component string, opts *kpt.CommandOptions, pkgOpts *kpt.PackageOptions) error {
+	switch {
+	case len(r.bundle) != 0 || source.IsLocal(pkgOpts.RepoURI):
+		if r.fSys.Exists(opts.Path) {
+			r.packageLogger(component).V(logging.LevelDebug).Info("Package already fetched", "path", opts.Path)
+
+			return nil
+		}
+
+		if len(r.bundle) != 0 {
+			return source.FetchArchive(r.fSys, r.bundle, pkgOpts.Path, opts.Path, r.bundleUpstream(component))
+		}
+
+		return source.Fetch(r.fSys, pkgOpts.RepoURI, pkgOpts.Path, opts.Path)
+	case r.cache == nil || r.fSys.Exists(opts.Path):
+		return r.PkgGet(r.phaseContext(), opts, kpt.NewPackage(pkgOpts))
+	}
+
+	src := &cache.Source{RepoURI: pkgOpts.RepoURI, Path: pkgOpts.Path, Ref: pkgOpts.Version}
+
+	cached, err := r.cache.Fetch(r.phaseContext(), src, opts.Path, func(path string) error {
+		fetchOpts := *opts
+		fetchOpts.Path = path
+
+		return r.PkgGet(r.phaseContext(), &fetchOpts, kpt.NewPackage(pkgOpts))
+	})
+	if err != nil {
 		return err
 	}
 
-	config := configMap.Data["app-config.nephio.yaml"]
+	r.packageLogger(component).V(logging.LevelDebug).Info("Package copied from the cache", "cached", cached)
+
+	return nil
+}
+
+// prepareComponent applies the customizations of the fetched component package.
+func (r *NephioRunner) prepareComponent(component string) error {
+	switch component {
+	case ComponentWebUI:
+		return r.step(StepCustomize, component, func(*kpt.CommandOptions) error {
+			return r.customizeWebUI()
+		})
+	case ComponentConfigSync:
+		// The upstream repository name is kept when the cluster isn't named
+		repoName := r.clusterName
+		if len(repoName) == 0 {
+			repoName = "${2}"
+		}
 
-	var backstageConfig map[string]interface{}
-	if err := yaml.Unmarshal([]byte(config), &backstageConfig); err != nil {
+		return r.step(StepCustomize, component, func(*kpt.CommandOptions) error {
+			return r.customizePackage(component, func() error {
+				return r.step(StepEval, component, func(opts *kpt.CommandOptions) error {
+					return r.FnEval(r.phaseContext(), opts, r.imageMirror.Rewrite(searchReplaceImage), "spec.git.repo",
+						"https://github.com/(.*)/(.*)", workloadRepo(r.gitServiceURI, repoName))
+				})
+			})
+		})
+	}
+
+	return r.step(StepCustomize, component, func(*kpt.CommandOptions) error {
+		return r.customizePackage(component, nil)
+	})
+}
+
+// preparePackage fetches, customizes and renders the component package, none of these steps
+// requires the cluster.
+func (r *NephioRunner) preparePackage(component string) error {
+	if err := r.getPackage(component); err != nil {
+		return err
+	}
+
+	return r.renderPackage(component)
+}
+
+// renderPackage customizes and renders the fetched component package.
+func (r *NephioRunner) renderPackage(component string) error {
+	if err := r.prepareComponent(component); err != nil {
 		return err
 	}
 
-	backend, ok := backstageConfig["backend"].(map[interface{}]interface{})
-	if !ok {
+	return r.step(StepRender, component, func(opts *kpt.CommandOptions) error {
+		if err := r.FnRender(r.phaseContext(), opts); err != nil {
+			return err
+		}
+
+		r.debugCmd(opts, r.PkgDiff)
+
 		return nil
+	})
+}
+
+// deployPackage applies the rendered component package and waits for its resources.
+func (r *NephioRunner) deployPackage(component string) error {
+	r.packageLogger(component).Info("Installing package")
+
+	if err := r.step(StepInit, component, func(opts *kpt.CommandOptions) error {
+		return r.LiveInit(r.phaseContext(), opts)
+	}); err != nil {
+		return err
 	}
 
-	backend["baseUrl"] = backendBaseUrl
-	backstageConfig["backend"] = backend
+	return r.step(StepApply, component, func(opts *kpt.CommandOptions) error {
+		if err := r.LiveApply(r.phaseContext(), opts); err != nil {
+			return err
+		}
+
+		if r.cluster == nil {
+			return nil
+		}
+
+		opts.Logger.V(logging.LevelDebug).Info("Waiting for the package resources")
+
+		return waitForPackage(r.phaseContext(), r.cluster, r.fSys, opts.Path,
+			r.reconcileTimeout, logging.Writer(opts.Logger))
+	})
+}
 
-	data, err := yaml.Marshal(backstageConfig)
+// Install installs the components provided as a DAG. The packages are prepared concurrently
+// and every package is applied once its own preparation and the applies of the components it
+// depends on succeed. The dependencies on components which aren't provided are ignored.
+func (r *NephioRunner) Install(components ...string) error {
+	requested := make(map[string]bool, len(components))
+	for _, component := range components {
+		requested[component] = true
+	}
+
+	tasks := make([]task, 0, 2*len(components))
+
+	for _, component := range components {
+		component := component
+		dependsOn := []string{component + "/prepare"}
+
+		for _, dependency := range componentDependencies[component] {
+			if requested[dependency] {
+				dependsOn = append(dependsOn, dependency+"/deploy")
+			}
+		}
+
+		tasks = append(tasks,
+			task{name: component + "/prepare", run: func() error { return r.preparePackage(component) }},
+			task{
+				name: component + "/deploy", dependsOn: dependsOn,
+				run: func() error { return r.deployPackage(component) },
+			},
+		)
+	}
+
+	return runTasks(r.phaseContext(), tasks, r.parallelism)
+}
+
+func (r *NephioRunner) InstallSystem() error {
+	return r.Install(ComponentSystem)
+}
+
+func (r *NephioRunner) setBackendBaseUrl(configMap *yaml.RNode) error {
+	config, err := configMap.Pipe(yaml.Lookup("data", "app-config.nephio.yaml"))
+	if err != nil || config == nil {
+		return err
+	}
+
+	backstageConfig, err := yaml.Parse(config.YNode().Value)
 	if err != nil {
 		return err
 	}
 
-	configMap.Data["app-config.nephio.yaml"] = string(data[:])
+	backend, err := backstageConfig.Pipe(yaml.Lookup("backend"))
+	if err != nil || backend == nil || backend.YNode().Kind != yaml.MappingNode {
+		return err
+	}
+
+	if err := backend.PipeE(yaml.SetField("baseUrl", yaml.NewStringRNode(r.backendBaseUrl))); err != nil {
+		return err
+	}
 
-	if err := r.writeResourceFunc(os.Create, filename, &configMap); err != nil {
+	data, err := backstageConfig.String()
+	if err != nil {
 		return err
 	}
 
+	config.YNode().Value = data
+
 	return nil
 }
 
-func (r *NephioRunner) setClusterType(filename, clusterType string) error {
-	var service v1.Service
+func (r *NephioRunner) setClusterType(service *yaml.RNode) error {
+	if err := service.PipeE(yaml.LookupCreate(yaml.MappingNode, "spec"),
+		yaml.SetField("type", yaml.NewStringRNode(r.webUIClusterType))); err != nil {
+		return err
+	}
 
-	if err := r.readResourceFunc(ioutil.ReadFile, filename, &service); err != nil {
+	if v1.ServiceType(r.webUIClusterType) != v1.ServiceTypeNodePort {
+		return nil
+	}
+
+	ports, err := service.Pipe(yaml.LookupCreate(yaml.SequenceNode, "spec", "ports"))
+	if err != nil {
 		return err
 	}
 
-	svcType := v1.ServiceType(clusterType)
-	service.Spec.Type = svcType
+	nodePort := yaml.NewRNode(&yaml.Node{
+		Kind: yaml.ScalarNode, Tag: yaml.NodeTagInt, Value: strconv.Itoa(DefaultWebUINodePort),
+	})
 
-	if svcType == v1.ServiceTypeNodePort {
-		if len(service.Spec.Ports) > 1 {
-			service.Spec.Ports[0].NodePort = DefaultWebUINodePort
-		} else {
-			service.Spec.Ports = append(service.Spec.Ports, v1.ServicePort{NodePort: DefaultWebUINodePort})
-		}
+	elements, err := ports.Elements()
+	if err != nil {
+		return err
 	}
 
-	if err := r.writeResourceFunc(os.Create, filename, &service); err != nil {
+	if len(elements) != 0 {
+		return elements[0].PipeE(yaml.SetField("nodePort", nodePort))
+	}
+
+	port := yaml.NewMapRNode(nil)
+	if err := port.PipeE(yaml.SetField("nodePort", nodePort)); err != nil {
 		return err
 	}
 
-	return nil
+	return ports.PipeE(yaml.Append(port.YNode()))
 }
 
-func (r *NephioRunner) InstallWebUI() error {
-	r.SetLocalPath(r.basePath + "/webui")
-	r.packageOptions.Path = "nephio-webui"
-
-	r.getPackage()
+func (r *NephioRunner) customizeWebUI() error {
+	customizations := []func(*k8s.Package) error{}
 
 	if len(r.backendBaseUrl) != 0 {
-		if err := r.setBackendBaseUrl(r.basePath+"/webui/config-map.yaml", r.backendBaseUrl); err != nil {
-			return err
+		customizations = append(customizations, func(pkg *k8s.Package) error {
+			return pkg.EditResource(webUIConfigMapID, r.setBackendBaseUrl)
+		})
+	}
+
+	if len(r.webUIClusterType) != 0 && r.webUIClusterType != string(v1.ServiceTypeClusterIP) {
+		customizations = append(customizations, func(pkg *k8s.Package) error {
+			return pkg.EditResource(webUIServiceID, r.setClusterType)
+		})
+	}
+
+	return r.customizePackage(ComponentWebUI, nil, customizations...)
+}
+
+// patchPackage returns the customizations applying the user patches of the component provided,
+// which are stored in a subdirectory of the patches directory named after the component.
+func (r *NephioRunner) patchPackage(component string) ([]func(*k8s.Package) error, error) {
+	if len(r.patchesDir) == 0 {
+		return nil, nil
+	}
+
+	if !r.fSys.IsDir(r.patchesDir) {
+		return nil, errors.Errorf("the %s patches directory doesn't exist", r.patchesDir)
+	}
+
+	path := filepath.Join(r.patchesDir, component)
+	if !r.fSys.IsDir(path) {
+		return nil, nil
+	}
+
+	patches, err := k8s.ReadPatches(r.fSys, path)
+	if err != nil {
+		return nil, err
+	}
+
+	customizations := make([]func(*k8s.Package) error, 0, len(patches))
+
+	for i := range patches {
+		patch := patches[i]
+
+		customizations = append(customizations, func(pkg *k8s.Package) error {
+			patched, err := pkg.ApplyPatch(&patch)
+			if err != nil {
+				return err
+			}
+
+			r.packageLogger(component).Info("Applied patch", "patch", patch.Source, "type", patch.Type(),
+				"resources", patched, "target", patch.Target.String())
+
+			return nil
+		})
+	}
+
+	return customizations, nil
+}
+
+// customizePackage runs the function evaluation and applies the customizations provided and the
+// user patches to the component package within a transaction, so the package is rolled back to
+// its fetched state when any of them fails.
+func (r *NephioRunner) customizePackage(component string, eval func() error,
+	customizations ...func(*k8s.Package) error,
+) error {
+	patches, err := r.patchPackage(component)
+	if err != nil {
+		return err
+	}
+
+	customizations = append(customizations, patches...)
+	if r.imageMirror.Enabled() {
+		customizations = append(customizations, r.mirrorImages(component))
+	}
+
+	if eval == nil && len(customizations) == 0 {
+		return nil
+	}
+
+	path := r.packagePath(component)
+
+	tx, err := k8s.BeginTransaction(r.fSys, path)
+	if err != nil {
+		return err
+	}
+
+	if eval != nil {
+		err = eval()
+	}
+
+	if err == nil && len(customizations) != 0 {
+		err = applyCustomizations(tx, path, customizations)
+	}
+
+	if err == nil && r.imageMirror.Enabled() {
+		err = k8s.RewriteFunctionImages(tx, path, r.imageMirror.Rewrite)
+	}
+
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return errors.Wrapf(rollbackErr, "failed to roll back the %s package after %v", path, err)
 		}
+
+		return errors.Wrapf(err, "failed to customize the %s package", path)
 	}
 
-	if r.webUIClusterType != string(v1.ServiceTypeClusterIP) {
-		if err := r.setClusterType(r.basePath+"/webui/service.yaml", r.webUIClusterType); err != nil {
+	return tx.Commit()
+}
+
+// mirrorImages rewrites the container images of the component package workloads.
+func (r *NephioRunner) mirrorImages(component string) func(*k8s.Package) error {
+	return func(pkg *k8s.Package) error {
+		count, err := pkg.RewriteImages(r.imageMirror.Rewrite)
+		if err != nil {
 			return err
 		}
+
+		r.packageLogger(component).V(logging.LevelDebug).Info("Rewrote the container images", "images", count)
+
+		return nil
 	}
+}
 
-	r.installPackage()
+func applyCustomizations(fSys filesys.FileSystem, path string, customizations []func(*k8s.Package) error) error {
+	pkg, err := k8s.ReadPackage(fSys, path)
+	if err != nil {
+		return err
+	}
 
-	return nil
+	for _, customize := range customizations {
+		if err := customize(pkg); err != nil {
+			return err
+		}
+	}
+
+	return pkg.Write()
 }
 
-func (r *NephioRunner) InstallConfigSync() {
-	r.SetLocalPath(r.basePath + "/configsync")
-	r.packageOptions.Path = "nephio-configsync"
+func (r *NephioRunner) InstallWebUI() error {
+	return r.Install(ComponentWebUI)
+}
 
-	r.getPackage()
-	r.FnEval("gcr.io/kpt-fn/search-replace:v0.2", "spec.git.repo",
-		"https://github.com/(.*)/(.*)", r.gitServiceURI+"/${2}")
-	r.installPackage()
+func (r *NephioRunner) InstallConfigSync() error {
+	return r.Install(ComponentConfigSync)
 }
@@ -17,29 +17,46 @@ limitations under the License.
 package app_test
 
 import (
-	"bytes"
 	"errors"
-	"io/ioutil"
-	"os"
+	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/electrocucaracha/nephioadm/internal/app"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
-	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
 
-func fakeReadYamlFile(filename string) ([]byte, error) {
+func newFakeFileSystem() filesys.FileSystem {
+	fSys := filesys.MakeFsInMemory()
+	writePackageFiles(fSys, app.DefaultBasePath)
+
+	return fSys
+}
+
+// writePackageFiles writes the content of the fetched packages the customizations rely on.
+func writePackageFiles(fSys filesys.FileSystem, basePath string) {
 	testdata := map[string]string{
-		"/opt/nephio/webui/config-map.yaml": `apiVersion: v1
+		"webui/config-map.yaml": `apiVersion: v1
 kind: ConfigMap
-metadata:
+metadata: # kpt-merge: nephio-webui/nephio-webui-config
   name: nephio-webui-config
 data:
   app-config.nephio.yaml: |
     backend:
-      baseUrl: http://localhost:7007`,
-		"/opt/nephio/webui/service.yaml": `apiVersion: v1
+      # Used for enabling authentication
+      baseUrl: http://localhost:7007
+`,
+		"system/deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: package-deployment-controller
+  namespace: nephio-system
+spec:
+  replicas: 1
+`,
+		"webui/nephio-webui.yaml": `apiVersion: v1
 kind: Service
 metadata:
   name: nephio-webui
@@ -50,38 +67,27 @@ spec:
   ports:
     - name: http
       port: 7007
-      targetPort: http`,
-	}
-
-	val, ok := testdata[filename]
-	if ok {
-		return ioutil.ReadAll(bytes.NewBufferString(val))
-	}
-
-	return nil, errors.New("non-existing file")
-}
-
-func fakeWriteResourceToFile(createFunc func(string) (*os.File, error),
-	path string, resource runtime.Object,
-) error {
-	return nil
-}
-
-func fakeReadResourceFromFile(readYamlFunc func(string) ([]byte, error), path string, into interface{}) error {
-	data, err := fakeReadYamlFile(path)
-	if err != nil {
-		return err
+      targetPort: http
+`,
+		"configsync/root-sync.yaml": `apiVersion: configsync.gke.io/v1beta1
+kind: RootSync
+metadata:
+  name: root-sync
+  namespace: config-management-system
+spec:
+  git:
+    repo: https://github.com/nephio-project/nephio-packages
+`,
 	}
 
-	if err := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), len(data)).Decode(&into); err != nil {
-		return err
+	for path, content := range testdata {
+		path = filepath.Join(basePath, path)
+		Expect(fSys.MkdirAll(filepath.Dir(path))).To(Succeed())
+		Expect(fSys.WriteFile(path, []byte(content))).To(Succeed())
 	}
-
-	return nil
 }
 
 func (c *mockClient) checkCallerCountsFromRunner(debug bool) {
-	Expect(c.SetLocalPathCallerCount).Should(Equal(1))
 	Expect(c.PkgGetCallerCount).Should(Equal(1))
 	Expect(c.FnRenderCallerCount).Should(Equal(1))
 	Expect(c.LiveInitCallerCount).Should(Equal(1))
@@ -90,19 +96,23 @@ func (c *mockClient) checkCallerCountsFromRunner(debug bool) {
 	if debug {
 		Expect(c.PkgTreeCallerCount).Should(Equal(1))
 		Expect(c.PkgDiffCallerCount).Should(Equal(1))
-		Expect(c.LiveStatusCallerCount).Should(Equal(1))
 	} else {
 		Expect(c.PkgTreeCallerCount).Should(Equal(0))
 		Expect(c.PkgDiffCallerCount).Should(Equal(0))
-		Expect(c.LiveStatusCallerCount).Should(Equal(0))
 	}
 }
 
 var _ = Describe("Nephio Runner", func() {
+	var fSys filesys.FileSystem
+
+	BeforeEach(func() {
+		fSys = newFakeFileSystem()
+	})
+
 	DescribeTable("install System package", func(debug bool, args ...string) {
 		client := NewMockClient()
-		app.NewRunner(client, fakeReadResourceFromFile, fakeWriteResourceToFile,
-			&app.NephioRunnerOptions{Debug: debug}).InstallSystem()
+		err := app.NewRunner(client, fSys, &app.NephioRunnerOptions{Debug: debug}).InstallSystem()
+		Expect(err).NotTo(HaveOccurred())
 		client.checkCallerCountsFromRunner(debug)
 		Expect(client.FnEvalCallerCount).Should(Equal(0))
 	},
@@ -110,13 +120,27 @@ var _ = Describe("Nephio Runner", func() {
 		Entry("when the no options are provided and debug is disabled", false),
 	)
 
+	DescribeTable("ConfigSync repository", func(opts *app.NephioRunnerOptions, expected string) {
+		client := NewMockClient()
+		Expect(app.NewRunner(client, fSys, opts).InstallConfigSync()).To(Succeed())
+
+		Expect(client.FnEvalValues).To(Equal([]string{expected}))
+	},
+		Entry("when the cluster is named", &app.NephioRunnerOptions{
+			GitServiceURI: app.DefaultGitServiceURI, ClusterName: "regional",
+		}, "https://github.com/nephio-test/regional"),
+		Entry("when the cluster isn't named", &app.NephioRunnerOptions{
+			GitServiceURI: "http://gitea:3000/nephio",
+		}, "http://gitea:3000/nephio/${2}"),
+	)
+
 	DescribeTable("install Web UI package", func(debug bool, args ...string) {
 		client := NewMockClient()
 		opts := &app.NephioRunnerOptions{Debug: debug}
 		if len(args) > 1 {
 			opts.BackendBaseUrl = args[0]
 		}
-		err := app.NewRunner(client, fakeReadResourceFromFile, fakeWriteResourceToFile, opts).InstallWebUI()
+		err := app.NewRunner(client, fSys, opts).InstallWebUI()
 		Expect(err).NotTo(HaveOccurred())
 		client.checkCallerCountsFromRunner(debug)
 		Expect(client.FnEvalCallerCount).Should(Equal(0))
@@ -126,14 +150,186 @@ var _ = Describe("Nephio Runner", func() {
 		Entry("when a backend base URL is provided", false, "https://codespace-7007.preview.app.github.dev"),
 	)
 
+	It("should run the kpt commands of concurrent installations on their own package", func() {
+		client := NewMockClient()
+		runner := app.NewRunner(client, fSys, &app.NephioRunnerOptions{})
+
+		var wg sync.WaitGroup
+		errs := make([]error, 2)
+
+		for i, install := range []func() error{runner.InstallWebUI, runner.InstallConfigSync} {
+			wg.Add(1)
+
+			go func(i int, install func() error) {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				errs[i] = install()
+			}(i, install)
+		}
+
+		wg.Wait()
+
+		Expect(errs).To(HaveEach(BeNil()))
+		Expect(client.Commands).To(ConsistOf(
+			"pkg get /opt/nephio/webui", "fn render /opt/nephio/webui",
+			"live init /opt/nephio/webui", "live apply /opt/nephio/webui",
+			"pkg get /opt/nephio/configsync", "fn eval /opt/nephio/configsync", "fn render /opt/nephio/configsync",
+			"live init /opt/nephio/configsync", "live apply /opt/nephio/configsync",
+		))
+	})
+
+	It("should prepare the independent packages while their dependencies are applied", func() {
+		client := NewMockClient()
+		webUIRendered := make(chan struct{})
+		client.OnCommand = func(command string) {
+			switch command {
+			case "fn render /opt/nephio/webui":
+				close(webUIRendered)
+			case "live apply /opt/nephio/system":
+				// The Web UI package is rendered concurrently, so the apply is only released by it
+				<-webUIRendered
+			}
+		}
+
+		runner := app.NewRunner(client, fSys, &app.NephioRunnerOptions{Parallelism: 2})
+		Expect(runner.Install(app.ComponentSystem, app.ComponentWebUI)).To(Succeed())
+
+		Expect(client.Commands).To(HaveLen(8))
+		Expect(client.Commands[6:]).To(Equal([]string{"live init /opt/nephio/webui", "live apply /opt/nephio/webui"}))
+	})
+
+	It("should skip the packages whose dependencies fail", func() {
+		client := NewMockClient()
+		client.LiveApplyErr = errors.New("apply failed")
+
+		runner := app.NewRunner(client, fSys, &app.NephioRunnerOptions{Parallelism: 2})
+		Expect(runner.Install(app.ComponentSystem, app.ComponentWebUI)).To(MatchError("apply failed"))
+
+		Expect(client.Commands).To(ContainElement("fn render /opt/nephio/webui"))
+		Expect(client.Commands).NotTo(ContainElement("live init /opt/nephio/webui"))
+	})
+
+	Describe("customize Web UI package", func() {
+		It("should keep the comments of the edited resources", func() {
+			opts := &app.NephioRunnerOptions{
+				BackendBaseUrl:   "https://codespace-7007.preview.app.github.dev",
+				WebUIClusterType: "NodePort",
+			}
+			Expect(app.NewRunner(NewMockClient(), fSys, opts).InstallWebUI()).To(Succeed())
+
+			Expect(fSys.ReadFile("/opt/nephio/webui/config-map.yaml")).To(BeEquivalentTo(`apiVersion: v1
+kind: ConfigMap
+metadata: # kpt-merge: nephio-webui/nephio-webui-config
+  name: nephio-webui-config
+data:
+  app-config.nephio.yaml: |
+    backend:
+      # Used for enabling authentication
+      baseUrl: https://codespace-7007.preview.app.github.dev
+`))
+			Expect(fSys.ReadFile("/opt/nephio/webui/nephio-webui.yaml")).To(BeEquivalentTo(`apiVersion: v1
+kind: Service
+metadata:
+  name: nephio-webui
+  namespace: nephio-webui
+spec:
+  selector:
+    app: nephio-webui
+  ports:
+    - name: http
+      port: 7007
+      targetPort: http
+      nodePort: 30007
+  type: NodePort
+`))
+		})
+	})
+
+	It("should roll back the Web UI package when a customization fails", func() {
+		Expect(fSys.RemoveAll("/opt/nephio/webui/nephio-webui.yaml")).To(Succeed())
+		original, err := fSys.ReadFile("/opt/nephio/webui/config-map.yaml")
+		Expect(err).NotTo(HaveOccurred())
+
+		opts := &app.NephioRunnerOptions{
+			BackendBaseUrl:   "https://codespace-7007.preview.app.github.dev",
+			WebUIClusterType: "NodePort",
+		}
+		Expect(app.NewRunner(NewMockClient(), fSys, opts).InstallWebUI()).NotTo(Succeed())
+
+		Expect(fSys.ReadFile("/opt/nephio/webui/config-map.yaml")).To(Equal(original))
+		Expect(fSys.Exists("/opt/nephio/.webui.backup")).To(BeFalse())
+	})
+
+	It("should report the package apply failures", func() {
+		client := NewMockClient()
+		client.LiveApplyErr = errors.New("1 resource(s) failed to be applied")
+
+		err := app.NewRunner(client, fSys, &app.NephioRunnerOptions{Debug: true}).InstallSystem()
+
+		Expect(err).To(MatchError(client.LiveApplyErr))
+	})
+
+	DescribeTable("reconcile timeout", func(timeout, expected time.Duration) {
+		client := NewMockClient()
+
+		runner := app.NewRunner(client, fSys, &app.NephioRunnerOptions{ReconcileTimeout: timeout})
+		Expect(runner.InstallSystem()).To(Succeed())
+
+		Expect(client.ReconcileTimeout).To(Equal(expected))
+	},
+		Entry("should apply with the default timeout", time.Duration(0), app.DefaultReconcileTimeout),
+		Entry("should apply with the configured timeout", 2*time.Minute, 2*time.Minute),
+	)
+
 	DescribeTable("install ConfigSync package", func(debug bool, args ...string) {
 		client := NewMockClient()
-		app.NewRunner(client, fakeReadResourceFromFile, fakeWriteResourceToFile,
-			&app.NephioRunnerOptions{Debug: debug}).InstallConfigSync()
+		err := app.NewRunner(client, fSys, &app.NephioRunnerOptions{Debug: debug}).InstallConfigSync()
+		Expect(err).NotTo(HaveOccurred())
 		client.checkCallerCountsFromRunner(debug)
 		Expect(client.FnEvalCallerCount).Should(Equal(1))
 	},
 		Entry("when the no options are provided", true),
 		Entry("when the no options are provided and debug is disabled", false),
 	)
+
+	Describe("patch packages", func() {
+		BeforeEach(func() {
+			Expect(fSys.WriteFile("/tmp/patches/system/replicas.yaml", []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: package-deployment-controller
+spec:
+  replicas: 2
+`))).To(Succeed())
+		})
+
+		It("should apply the patches of the package", func() {
+			opts := &app.NephioRunnerOptions{PatchesDir: "/tmp/patches"}
+			Expect(app.NewRunner(NewMockClient(), fSys, opts).InstallSystem()).To(Succeed())
+
+			Expect(fSys.ReadFile("/opt/nephio/system/deployment.yaml")).To(ContainSubstring("replicas: 2"))
+		})
+
+		It("should fail when a patch target doesn't match", func() {
+			Expect(fSys.WriteFile("/tmp/patches/system/env.yaml", []byte(`target:
+  kind: Deployment
+  name: non-existing
+patch: |-
+  - op: add
+    path: /spec/paused
+    value: true
+`))).To(Succeed())
+
+			opts := &app.NephioRunnerOptions{PatchesDir: "/tmp/patches"}
+			Expect(app.NewRunner(NewMockClient(), fSys, opts).InstallSystem()).NotTo(Succeed())
+
+			Expect(fSys.ReadFile("/opt/nephio/system/deployment.yaml")).To(ContainSubstring("replicas: 1"))
+		})
+
+		It("should fail when the patches directory doesn't exist", func() {
+			opts := &app.NephioRunnerOptions{PatchesDir: "/tmp/non-existing"}
+			Expect(app.NewRunner(NewMockClient(), fSys, opts).InstallSystem()).NotTo(Succeed())
+		})
+	})
 })
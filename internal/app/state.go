@@ -0,0 +1,255 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/electrocucaracha/nephioadm/internal/k8s"
+	"github.com/electrocucaracha/nephioadm/internal/kpt"
+	"github.com/electrocucaracha/nephioadm/internal/version"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	StateNamespace = "nephioadm-system"
+	StateConfigMap = "nephioadm-state"
+	StateLabel     = "nephioadm.nephio.org/install-state"
+
+	// stateKey is the ConfigMap entry which contains the encoded installation state
+	stateKey = "state.yaml"
+)
+
+var namespaceGVR = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
+// InstallState records what nephioadm installed in a cluster, so it can be managed without the
+// local base path.
+type InstallState struct {
+	// NephioadmVersion is the release of the last nephioadm run which updated the cluster
+	NephioadmVersion string           `json:"nephioadmVersion"`
+	Created          time.Time        `json:"created"`
+	Updated          time.Time        `json:"updated"`
+	Components       []ComponentState `json:"components"`
+	Customizations   Customizations   `json:"customizations"`
+
+	// resourceVersion is the version of the recorded state, so its update fails when another run
+	// changed it in between
+	resourceVersion string
+}
+
+// ComponentState records the package revision of an installed component.
+type ComponentState struct {
+	Name      string `json:"name"`
+	Phase     string `json:"phase"`
+	RepoURI   string `json:"repo"`
+	Directory string `json:"directory"`
+	// Version is the requested git reference, it's empty when the default branch was installed
+	Version string `json:"version,omitempty"`
+	// Commit is the commit locked in the Kptfile upstreamLock, it's empty when the package wasn't
+	// fetched from a git repository
+	Commit    string    `json:"commit,omitempty"`
+	Installed time.Time `json:"installed"`
+	// Inventory identifies the applied resources, so they're managed without the local package
+	Inventory *PackageInventory `json:"inventory,omitempty"`
+}
+
+// Customizations are the options applied to the installed packages.
+type Customizations struct {
+	WebUIClusterType string `json:"webuiClusterType,omitempty"`
+	BackendBaseURL   string `json:"backendBaseUrl,omitempty"`
+	GitServiceURI    string `json:"gitServiceUri,omitempty"`
+}
+
+func (s *InstallState) setComponent(component ComponentState) {
+	for i := range s.Components {
+		if s.Components[i].Name == component.Name {
+			s.Components[i] = component
+
+			return
+		}
+	}
+
+	s.Components = append(s.Components, component)
+}
+
+// component returns the recorded state of the component provided, it's nil when it wasn't recorded.
+func (s *InstallState) component(name string) *ComponentState {
+	if s == nil {
+		return nil
+	}
+
+	for i := range s.Components {
+		if s.Components[i].Name == name {
+			return &s.Components[i]
+		}
+	}
+
+	return nil
+}
+
+func (s *InstallState) record() (*unstructured.Unstructured, error) {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode the installation state")
+	}
+
+	record := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": configMapGVR.GroupVersion().String(),
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":      StateConfigMap,
+			"namespace": StateNamespace,
+			"labels":    map[string]interface{}{StateLabel: "true"},
+		},
+		"data": map[string]interface{}{
+			stateKey: string(data),
+		},
+	}}
+	record.SetResourceVersion(s.resourceVersion)
+
+	return record, nil
+}
+
+// readInstallState retrieves the installation state recorded in the cluster, it's nil when
+// nephioadm hasn't installed anything yet.
+func readInstallState(ctx context.Context, cluster k8s.ClusterClient) (*InstallState, error) {
+	record, err := cluster.GetResource(ctx, configMapGVR, StateNamespace, StateConfigMap)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve the installation state")
+	}
+
+	data, _, _ := unstructured.NestedString(record.Object, "data", stateKey)
+
+	state := &InstallState{resourceVersion: record.GetResourceVersion()}
+	if err := yaml.Unmarshal([]byte(data), state); err != nil {
+		return nil, errors.Wrap(err, "failed to decode the installation state")
+	}
+
+	return state, nil
+}
+
+// recordState updates the installation state of the cluster with the components of the phase
+// provided and the customizations applied to them. The update is retried on top of the latest
+// state when another run changed it in between.
+func (r *NephioRunner) recordState(phase string) error {
+	ctx := r.phaseContext()
+
+	if err := r.cluster.ApplyResource(ctx, namespaceGVR, &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Namespace",
+		"metadata":   map[string]interface{}{"name": StateNamespace},
+	}}); err != nil {
+		return errors.Wrapf(err, "failed to create the %s namespace", StateNamespace)
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		return r.updateState(ctx, phase)
+	})
+}
+
+func (r *NephioRunner) updateState(ctx context.Context, phase string) error {
+	state, err := readInstallState(ctx, r.cluster)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	if state == nil {
+		state = &InstallState{Created: now}
+	}
+
+	state.NephioadmVersion = version.Get()
+	state.Updated = now
+
+	for _, component := range phaseComponents[phase] {
+		inventory, err := packageInventory(r.fSys, r.packagePath(component))
+		if err != nil {
+			return err
+		}
+
+		state.setComponent(ComponentState{
+			Name:      component,
+			Phase:     phase,
+			RepoURI:   r.repoURI,
+			Directory: componentPackages[component],
+			Version:   r.repoRef,
+			Commit:    kpt.LockedCommit(r.fSys, r.packagePath(component)),
+			Installed: now,
+			Inventory: inventory,
+		})
+	}
+
+	switch phase {
+	case PhaseInit:
+		state.Customizations.WebUIClusterType = r.webUIClusterType
+		state.Customizations.BackendBaseURL = r.backendBaseUrl
+	case PhaseJoin:
+		state.Customizations.GitServiceURI = r.gitServiceURI
+	}
+
+	record, err := state.record()
+	if err != nil {
+		return err
+	}
+
+	return errors.Wrap(r.cluster.ApplyResource(ctx, configMapGVR, record), "failed to record the installation state")
+}
+
+// recordedState retrieves the installation state recorded in the cluster provided, it's empty
+// when nephioadm hasn't installed anything yet.
+func (p NephioProvider) recordedState(ctx context.Context, opts *k8s.ClusterOptions) (*InstallState, error) {
+	cluster, err := p.newCluster(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to the cluster")
+	}
+
+	state, err := readInstallState(ctx, cluster)
+	if err == nil && state == nil {
+		state = &InstallState{}
+	}
+
+	return state, err
+}
+
+// InstallState retrieves what nephioadm installed in the cluster provided.
+func (p NephioProvider) InstallState(ctx context.Context, opts *k8s.ClusterOptions) (*InstallState, error) {
+	cluster, err := p.newCluster(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to the cluster")
+	}
+
+	state, err := readInstallState(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	if state == nil {
+		return nil, errors.New("nephioadm hasn't recorded any installation in the cluster")
+	}
+
+	return state, nil
+}
@@ -0,0 +1,120 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app_test
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/electrocucaracha/nephioadm/internal/app"
+	"github.com/electrocucaracha/nephioadm/internal/k8s"
+	"github.com/electrocucaracha/nephioadm/internal/version"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+)
+
+var _ = Describe("Installation state", func() {
+	var provider *app.NephioProvider
+	var cluster *mockCluster
+	var opts *app.NephioRunnerOptions
+
+	BeforeEach(func() {
+		fSys := newFakeFileSystem()
+		Expect(fSys.WriteFile(filepath.Join(app.DefaultBasePath, "system", "Kptfile"),
+			[]byte("kind: Kptfile\nupstreamLock:\n  git:\n    commit: "+lockedCommit+"\n"))).To(Succeed())
+		Expect(fSys.WriteFile(filepath.Join(app.DefaultBasePath, "system", "resourcegroup.yaml"),
+			[]byte(systemInventory+"  labels:\n    cli-utils.sigs.k8s.io/inventory-id: system-id\n"))).To(Succeed())
+
+		cluster = NewMockCluster()
+		provider = app.NewProvider(NewMockClient(), fSys, cluster.newCluster)
+		opts = &app.NephioRunnerOptions{
+			NephioRepoURI:    app.DefaultNephioRepoURI,
+			NephioRepoRef:    "v1.0.1",
+			GitServiceURI:    "http://gitea:3000/nephio-test",
+			BackendBaseUrl:   "http://localhost:7007",
+			WebUIClusterType: "NodePort",
+			SkipVerify:       true,
+		}
+	})
+
+	It("should record the components installed on every phase", func() {
+		Expect(provider.Init(context.Background(), opts)).To(Succeed())
+		Expect(provider.Join(context.Background(), opts)).To(Succeed())
+
+		Expect(cluster.Resources).To(HaveKey("Namespace/" + app.StateNamespace))
+
+		state, err := provider.InstallState(context.Background(), &k8s.ClusterOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.NephioadmVersion).To(Equal(version.Get()))
+		Expect(state.Updated).NotTo(BeTemporally("<", state.Created))
+		Expect(state.Customizations).To(Equal(app.Customizations{
+			WebUIClusterType: "NodePort",
+			BackendBaseURL:   "http://localhost:7007",
+			GitServiceURI:    "http://gitea:3000/nephio-test",
+		}))
+		Expect(state.Components).To(HaveLen(3))
+		Expect(state.Components[0]).To(MatchFields(IgnoreExtras, Fields{
+			"Name": Equal(app.ComponentSystem), "Phase": Equal(app.PhaseInit),
+			"RepoURI": Equal(app.DefaultNephioRepoURI), "Directory": Equal("nephio-system"),
+			"Version": Equal("v1.0.1"), "Commit": Equal(lockedCommit),
+		}))
+		Expect(state.Components[2]).To(MatchFields(IgnoreExtras, Fields{
+			"Name": Equal(app.ComponentConfigSync), "Phase": Equal(app.PhaseJoin), "Commit": BeEmpty(),
+		}))
+	})
+
+	It("should keep the components once they're installed again", func() {
+		Expect(provider.Init(context.Background(), opts)).To(Succeed())
+		Expect(provider.Init(context.Background(), opts)).To(Succeed())
+
+		state, err := provider.InstallState(context.Background(), &k8s.ClusterOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.Components).To(HaveLen(2))
+	})
+
+	It("should record the inventory of the applied packages", func() {
+		Expect(provider.Init(context.Background(), opts)).To(Succeed())
+
+		state, err := provider.InstallState(context.Background(), &k8s.ClusterOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.Components[0].Inventory).To(Equal(&app.PackageInventory{
+			Namespace: "nephio-system", Name: "inventory-system", ID: "system-id",
+		}))
+		Expect(state.Components[1].Inventory).To(BeNil())
+	})
+
+	It("should update the latest state when it changes concurrently", func() {
+		Expect(provider.Init(context.Background(), opts)).To(Succeed())
+		cluster.Conflicts = 2
+
+		Expect(provider.Join(context.Background(), opts)).To(Succeed())
+
+		Expect(cluster.Conflicts).To(BeZero())
+		Expect(cluster.Resources["ConfigMap/"+app.StateConfigMap].GetResourceVersion()).To(Equal("2"))
+
+		state, err := provider.InstallState(context.Background(), &k8s.ClusterOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.Components).To(HaveLen(3))
+	})
+
+	It("should fail when nothing was installed", func() {
+		_, err := provider.InstallState(context.Background(), &k8s.ClusterOptions{})
+
+		Expect(err).To(MatchError(ContainSubstring("hasn't recorded any installation")))
+	})
+})
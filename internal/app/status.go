@@ -0,0 +1,151 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/electrocucaracha/nephioadm/internal/k8s"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+type StatusOptions struct {
+	Cluster k8s.ClusterOptions
+	// BasePath contains the component packages, the components missing from the default one are
+	// reported from the installation state recorded in the cluster when it isn't provided
+	BasePath string
+}
+
+// ComponentStatus reports the installation state of a Nephio component.
+type ComponentStatus struct {
+	Name string
+	Path string
+	// Fetched reports whether the component package is stored in the base path
+	Fetched bool
+	// Recorded reports whether the component inventory was taken from the installation state instead
+	Recorded bool
+	Objects  []ObjectStatus
+	Message  string
+}
+
+// Ready reports whether the component package was applied and all its resources are reconciled.
+func (s ComponentStatus) Ready() bool {
+	if !(s.Fetched || s.Recorded) || len(s.Message) != 0 || len(s.Objects) == 0 {
+		return false
+	}
+
+	for _, object := range s.Objects {
+		if object.Status != status.CurrentStatus {
+			return false
+		}
+	}
+
+	return true
+}
+
+// allComponents lists the Nephio components in installation order.
+func allComponents() []string {
+	return append(append([]string{}, phaseComponents[PhaseInit]...), phaseComponents[PhaseJoin]...)
+}
+
+func componentStatus(ctx context.Context, cluster k8s.ClusterClient, fSys filesys.FileSystem,
+	basePath, component string,
+) ComponentStatus {
+	result := ComponentStatus{Name: component, Path: filepath.Join(basePath, component)}
+
+	result.Fetched = fSys.IsDir(result.Path)
+	if !result.Fetched {
+		return result
+	}
+
+	objects, err := inventoryObjects(ctx, cluster, fSys, result.Path)
+	result.setObjects(ctx, cluster, objects, err)
+
+	return result
+}
+
+// recordedStatus reports the state of a component from the inventory recorded in the cluster.
+func recordedStatus(ctx context.Context, cluster k8s.ClusterClient, component *ComponentState) ComponentStatus {
+	result := ComponentStatus{Name: component.Name, Recorded: true}
+
+	if component.Inventory == nil {
+		result.setObjects(ctx, cluster, nil, nil)
+
+		return result
+	}
+
+	objects, err := resourceGroupObjects(ctx, cluster, component.Inventory)
+	result.setObjects(ctx, cluster, objects, err)
+
+	return result
+}
+
+func (s *ComponentStatus) setObjects(ctx context.Context, cluster k8s.ClusterClient, objects []InventoryObject,
+	err error,
+) {
+	if err != nil {
+		s.Message = err.Error()
+
+		return
+	}
+
+	if len(objects) == 0 {
+		s.Message = "the package hasn't been applied"
+	}
+
+	for _, object := range objects {
+		s.Objects = append(s.Objects, objectStatus(ctx, cluster, object))
+	}
+}
+
+// Status reports the state of the Nephio components stored in the base path and their resources
+// in the cluster. Without a base path, the components which aren't stored in the default one are
+// reported from the installation state recorded in the cluster.
+func (p NephioProvider) Status(ctx context.Context, opts *StatusOptions) ([]ComponentStatus, error) {
+	cluster, err := p.newCluster(&opts.Cluster)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to the cluster")
+	}
+
+	var state *InstallState
+
+	basePath := opts.BasePath
+	if len(basePath) == 0 {
+		basePath = DefaultBasePath
+
+		if state, err = readInstallState(ctx, cluster); err != nil {
+			return nil, err
+		}
+	}
+
+	components := allComponents()
+	results := make([]ComponentStatus, 0, len(components))
+
+	for _, component := range components {
+		result := componentStatus(ctx, cluster, p.fSys, basePath, component)
+		if recorded := state.component(component); !result.Fetched && recorded != nil {
+			result = recordedStatus(ctx, cluster, recorded)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,108 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app_test
+
+import (
+	"context"
+
+	"github.com/electrocucaracha/nephioadm/internal/app"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+var _ = Describe("Status", func() {
+	var provider *app.NephioProvider
+	var cluster *mockCluster
+	var fSys filesys.FileSystem
+
+	BeforeEach(func() {
+		fSys = newFakeFileSystem()
+		Expect(fSys.WriteFile("/opt/nephio/system/resourcegroup.yaml", []byte(systemInventory))).To(Succeed())
+		Expect(fSys.MkdirAll("/opt/nephio/webui")).To(Succeed())
+		Expect(fSys.RemoveAll("/opt/nephio/configsync")).To(Succeed())
+
+		cluster = NewMockCluster()
+		cluster.AddObject("resourcegroups", &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "inventory-system", "namespace": "nephio-system"},
+			"spec": map[string]interface{}{
+				"resources": []interface{}{
+					map[string]interface{}{
+						"group": "apps", "kind": "Deployment",
+						"namespace": "nephio-system", "name": "package-deployment-controller",
+					},
+				},
+			},
+		}})
+		cluster.AddObject("deployments", newDeployment(1))
+
+		provider = app.NewProvider(NewMockClient(), fSys, cluster.newCluster)
+	})
+
+	It("should report the state of every component", func() {
+		results, err := provider.Status(context.Background(), &app.StatusOptions{})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(3))
+
+		Expect(results[0].Name).To(Equal(app.ComponentSystem))
+		Expect(results[0].Fetched).To(BeTrue())
+		Expect(results[0].Objects).To(HaveLen(1))
+		Expect(results[0].Objects[0].Status).To(Equal(status.CurrentStatus))
+		Expect(results[0].Ready()).To(BeTrue())
+
+		Expect(results[1].Name).To(Equal(app.ComponentWebUI))
+		Expect(results[1].Fetched).To(BeTrue())
+		Expect(results[1].Message).To(Equal("the package hasn't been applied"))
+		Expect(results[1].Ready()).To(BeFalse())
+
+		Expect(results[2].Name).To(Equal(app.ComponentConfigSync))
+		Expect(results[2].Fetched).To(BeFalse())
+		Expect(results[2].Ready()).To(BeFalse())
+	})
+
+	Describe("without the local packages", func() {
+		BeforeEach(func() {
+			Expect(fSys.RemoveAll("/opt/nephio/system")).To(Succeed())
+			cluster.RecordState(app.ComponentState{
+				Name: app.ComponentSystem, Phase: app.PhaseInit,
+				Inventory: &app.PackageInventory{Namespace: "nephio-system", Name: "inventory-system"},
+			})
+		})
+
+		It("should report the components recorded in the cluster", func() {
+			results, err := provider.Status(context.Background(), &app.StatusOptions{})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results[0].Fetched).To(BeFalse())
+			Expect(results[0].Recorded).To(BeTrue())
+			Expect(results[0].Objects).To(HaveLen(1))
+			Expect(results[0].Ready()).To(BeTrue())
+			Expect(results[2].Recorded).To(BeFalse())
+		})
+
+		It("should only report the packages of the base path provided", func() {
+			results, err := provider.Status(context.Background(), &app.StatusOptions{BasePath: "/opt/nephio"})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results[0].Recorded).To(BeFalse())
+			Expect(results[0].Ready()).To(BeFalse())
+		})
+	})
+})
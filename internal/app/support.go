@@ -0,0 +1,376 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/electrocucaracha/nephioadm/internal/k8s"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	k8sYaml "sigs.k8s.io/yaml"
+)
+
+const (
+	// LogsDir is the directory of the base path where the nephioadm logs are stored.
+	LogsDir = "logs"
+
+	redactedValue = "REDACTED"
+)
+
+var (
+	// nephioNamespaces lists the namespaces of the Nephio components.
+	nephioNamespaces = []string{
+		"nephio-system", "nephio-webui", "porch-system", "config-management-system", "resource-group-system",
+	}
+	// supportResources lists the resources collected from the Nephio namespaces.
+	supportResources = []schema.GroupVersionResource{
+		podGVR,
+		{Version: "v1", Resource: "services"},
+		configMapGVR,
+		secretGVR,
+		deploymentGVR,
+		{Group: "apps", Version: "v1", Resource: "replicasets"},
+		{Group: "apps", Version: "v1", Resource: "statefulsets"},
+		{Group: "apps", Version: "v1", Resource: "daemonsets"},
+		{Group: "batch", Version: "v1", Resource: "jobs"},
+	}
+	secretGVR = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+	nodeGVR   = schema.GroupVersionResource{Version: "v1", Resource: "nodes"}
+)
+
+type SupportBundleOptions struct {
+	Cluster  k8s.ClusterOptions
+	BasePath string
+	Output   string
+}
+
+// supportBundle writes the collected diagnostics into a gzipped tarball. Failures collecting
+// an item don't abort the process, they are recorded in the errors.txt file of the bundle.
+type supportBundle struct {
+	tar       *tar.Writer
+	timestamp time.Time
+	failures  []string
+}
+
+func (b *supportBundle) add(name string, data []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    int64(len(data)),
+		ModTime: b.timestamp,
+	}
+
+	if err := b.tar.WriteHeader(header); err != nil {
+		return errors.Wrapf(err, "failed to write the %s bundle header", name)
+	}
+
+	if _, err := b.tar.Write(data); err != nil {
+		return errors.Wrapf(err, "failed to write the %s bundle file", name)
+	}
+
+	return nil
+}
+
+func (b *supportBundle) addYAML(name string, value interface{}) error {
+	data, err := k8sYaml.Marshal(value)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal the %s bundle file", name)
+	}
+
+	return b.add(name, data)
+}
+
+func (b *supportBundle) recordFailure(err error) {
+	b.failures = append(b.failures, err.Error())
+}
+
+// redactSecret replaces the values of the Secret data.
+func redactSecret(secret *unstructured.Unstructured) {
+	for _, field := range []string{"data", "stringData"} {
+		data, found, _ := unstructured.NestedMap(secret.Object, field)
+		if !found {
+			continue
+		}
+
+		for key := range data {
+			data[key] = redactedValue
+		}
+
+		_ = unstructured.SetNestedMap(secret.Object, data, field)
+	}
+
+	annotations := secret.GetAnnotations()
+	delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
+	secret.SetAnnotations(annotations)
+}
+
+// redactSecretFile replaces the values of the Secret resources stored in the YAML file provided.
+func redactSecretFile(data []byte) ([]byte, error) {
+	nodes, err := (&kio.ByteReader{Reader: bytes.NewReader(data), OmitReaderAnnotations: true}).Read()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the resources")
+	}
+
+	redacted := false
+
+	for _, node := range nodes {
+		if node.GetKind() != "Secret" {
+			continue
+		}
+
+		for _, field := range []string{"data", "stringData"} {
+			values := node.Field(field)
+			if values == nil || values.Value.YNode().Kind != yaml.MappingNode {
+				continue
+			}
+
+			if err := values.Value.VisitFields(func(field *yaml.MapNode) error {
+				field.Value.YNode().Value = redactedValue
+
+				return nil
+			}); err != nil {
+				return nil, errors.Wrap(err, "failed to redact the secret")
+			}
+
+			redacted = true
+		}
+	}
+
+	if !redacted {
+		return data, nil
+	}
+
+	var out bytes.Buffer
+	if err := (&kio.ByteWriter{Writer: &out}).Write(nodes); err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the resources")
+	}
+
+	return out.Bytes(), nil
+}
+
+func (b *supportBundle) collectEvents(ctx context.Context, cluster k8s.ClusterClient) error {
+	events, err := cluster.ListResources(ctx, eventGVR, "", "")
+	if err != nil {
+		b.recordFailure(err)
+
+		return nil
+	}
+
+	return b.addYAML("cluster/events.yaml", events)
+}
+
+func (b *supportBundle) collectNodes(ctx context.Context, cluster k8s.ClusterClient) error {
+	nodes, err := cluster.ListResources(ctx, nodeGVR, "", "")
+	if err != nil {
+		b.recordFailure(err)
+
+		return nil
+	}
+
+	conditions := map[string]interface{}{}
+
+	for _, node := range nodes {
+		conditions[node.GetName()], _, _ = unstructured.NestedSlice(node.Object, "status", "conditions")
+	}
+
+	return b.addYAML("cluster/nodes.yaml", conditions)
+}
+
+func (b *supportBundle) collectPodLogs(ctx context.Context, cluster k8s.ClusterClient,
+	pod *unstructured.Unstructured,
+) error {
+	for _, field := range []string{"initContainers", "containers"} {
+		containers, _, _ := unstructured.NestedSlice(pod.Object, "spec", field)
+
+		for _, item := range containers {
+			container, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			name, _ := container["name"].(string)
+
+			logs, err := cluster.GetPodLogs(ctx, pod.GetNamespace(), pod.GetName(), name)
+			if err != nil {
+				b.recordFailure(err)
+
+				continue
+			}
+
+			if err := b.add(path.Join("cluster", "logs", pod.GetNamespace(), pod.GetName(), name+".log"),
+				logs); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (b *supportBundle) collectNamespace(ctx context.Context, cluster k8s.ClusterClient, namespace string) error {
+	for _, gvr := range supportResources {
+		resources, err := cluster.ListResources(ctx, gvr, namespace, "")
+		if err != nil {
+			b.recordFailure(err)
+
+			continue
+		}
+
+		if len(resources) == 0 {
+			continue
+		}
+
+		for i := range resources {
+			switch gvr {
+			case secretGVR:
+				redactSecret(&resources[i])
+			case podGVR:
+				if err := b.collectPodLogs(ctx, cluster, &resources[i]); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := b.addYAML(path.Join("cluster", "namespaces", namespace, gvr.Resource+".yaml"),
+			resources); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// collectPackages adds the files stored in the base path, which include the local packages and
+// the nephioadm logs.
+func (b *supportBundle) collectPackages(fSys filesys.FileSystem, basePath, output string) error {
+	if !fSys.IsDir(basePath) {
+		b.recordFailure(errors.Errorf("the %s base path doesn't exist", basePath))
+
+		return nil
+	}
+
+	return fSys.Walk(basePath, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			b.recordFailure(err)
+
+			return nil
+		}
+
+		if info.IsDir() || file == output {
+			return nil
+		}
+
+		data, err := fSys.ReadFile(file)
+		if err != nil {
+			b.recordFailure(err)
+
+			return nil
+		}
+
+		if ext := filepath.Ext(file); ext == ".yaml" || ext == ".yml" {
+			if data, err = redactSecretFile(data); err != nil {
+				b.recordFailure(errors.Wrapf(err, "failed to redact the %s file", file))
+
+				return nil
+			}
+		}
+
+		relPath, err := filepath.Rel(basePath, file)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get the relative path of the %s file", file)
+		}
+
+		return b.add(path.Join("base-path", filepath.ToSlash(relPath)), data)
+	})
+}
+
+// collectSupportBundle writes the diagnostics of the cluster and the base path into the output provided.
+func collectSupportBundle(ctx context.Context, cluster k8s.ClusterClient, fSys filesys.FileSystem,
+	opts *SupportBundleOptions, out io.Writer,
+) error {
+	gzipWriter := gzip.NewWriter(out)
+	bundle := &supportBundle{tar: tar.NewWriter(gzipWriter), timestamp: time.Now()}
+
+	basePath := opts.BasePath
+	if len(basePath) == 0 {
+		basePath = DefaultBasePath
+	}
+
+	collectors := []func() error{
+		func() error { return bundle.collectEvents(ctx, cluster) },
+		func() error { return bundle.collectNodes(ctx, cluster) },
+	}
+
+	for _, namespace := range nephioNamespaces {
+		namespace := namespace
+		collectors = append(collectors, func() error { return bundle.collectNamespace(ctx, cluster, namespace) })
+	}
+
+	collectors = append(collectors, func() error { return bundle.collectPackages(fSys, basePath, opts.Output) })
+
+	for _, collect := range collectors {
+		if err := collect(); err != nil {
+			return err
+		}
+	}
+
+	if len(bundle.failures) != 0 {
+		if err := bundle.add("errors.txt", []byte(strings.Join(bundle.failures, "\n")+"\n")); err != nil {
+			return err
+		}
+	}
+
+	if err := bundle.tar.Close(); err != nil {
+		return errors.Wrap(err, "failed to close the support bundle")
+	}
+
+	return errors.Wrap(gzipWriter.Close(), "failed to compress the support bundle")
+}
+
+// createLogFile creates the log file of a phase in the base path logs directory, so it can be
+// collected in a support bundle.
+func createLogFile(fSys filesys.FileSystem, basePath, name string) (filesys.File, error) {
+	if len(basePath) == 0 {
+		basePath = DefaultBasePath
+	}
+
+	dir := filepath.Join(basePath, LogsDir)
+	if err := fSys.MkdirAll(dir); err != nil {
+		return nil, errors.Wrapf(err, "failed to create the %s logs directory", dir)
+	}
+
+	file, err := fSys.Create(filepath.Join(dir, name+"-"+time.Now().Format("20060102150405")+".log"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the log file")
+	}
+
+	return file, nil
+}
@@ -0,0 +1,122 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"strings"
+
+	"github.com/electrocucaracha/nephioadm/internal/app"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// readBundle returns the content of the files stored in the support bundle provided.
+func readBundle(fSys filesys.FileSystem, path string) map[string]string {
+	data, err := fSys.ReadFile(path)
+	Expect(err).NotTo(HaveOccurred())
+
+	gzipReader, err := gzip.NewReader(bytes.NewReader(data))
+	Expect(err).NotTo(HaveOccurred())
+
+	files := map[string]string{}
+	reader := tar.NewReader(gzipReader)
+
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		Expect(err).NotTo(HaveOccurred())
+
+		content, err := io.ReadAll(reader)
+		Expect(err).NotTo(HaveOccurred())
+		files[header.Name] = string(content)
+	}
+
+	return files
+}
+
+var _ = Describe("Support bundle", func() {
+	var provider *app.NephioProvider
+	var fSys filesys.FileSystem
+
+	BeforeEach(func() {
+		fSys = newFakeFileSystem()
+		Expect(fSys.WriteFile("/opt/nephio/system/secret.yaml", []byte(`apiVersion: v1
+kind: Secret
+metadata:
+  name: git-user-secret
+  namespace: nephio-system
+stringData:
+  password: secret
+`))).To(Succeed())
+
+		cluster := NewMockCluster()
+		cluster.AddObject("secrets", &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "git-user-secret", "namespace": "nephio-system"},
+			"data":     map[string]interface{}{"password": "c2VjcmV0"},
+		}})
+		cluster.AddObject("pods", &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "controller", "namespace": "nephio-system"},
+			"spec": map[string]interface{}{
+				"containers": []interface{}{map[string]interface{}{"name": "manager"}},
+			},
+		}})
+		cluster.AddObject("nodes", &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "nephio-control-plane"},
+			"status": map[string]interface{}{
+				"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}},
+			},
+		}})
+
+		provider = app.NewProvider(NewMockClient(), fSys, cluster.newCluster)
+	})
+
+	It("should collect the cluster diagnostics and the local packages", func() {
+		Expect(provider.Init(context.Background(), &app.NephioRunnerOptions{SkipVerify: true, WriteLogs: true})).To(Succeed())
+		Expect(provider.SupportBundle(context.Background(), &app.SupportBundleOptions{Output: "/tmp/bundle.tar.gz"})).To(Succeed())
+
+		files := readBundle(fSys, "/tmp/bundle.tar.gz")
+
+		Expect(files).To(HaveKeyWithValue("cluster/logs/nephio-system/controller/manager.log", "manager logs"))
+		Expect(files).To(HaveKeyWithValue("cluster/nodes.yaml", ContainSubstring("type: Ready")))
+		Expect(files).To(HaveKeyWithValue("cluster/namespaces/nephio-system/secrets.yaml",
+			ContainSubstring("password: REDACTED")))
+		Expect(files).To(HaveKeyWithValue("base-path/system/secret.yaml", ContainSubstring("password: REDACTED")))
+		Expect(files).To(HaveKey("base-path/webui/config-map.yaml"))
+
+		logs := []string{}
+		for name := range files {
+			if strings.HasPrefix(name, "base-path/logs/init-") {
+				logs = append(logs, name)
+			}
+		}
+		Expect(logs).To(HaveLen(1))
+
+		for name, content := range files {
+			Expect(content).NotTo(ContainSubstring("c2VjcmV0"), name)
+			Expect(content).NotTo(ContainSubstring("password: secret"), name)
+		}
+	})
+})
@@ -0,0 +1,68 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app_test
+
+import (
+	"context"
+	"github.com/electrocucaracha/nephioadm/internal/app"
+	"github.com/electrocucaracha/nephioadm/internal/tracing"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var _ = Describe("Phase tracing", func() {
+	var recorder *tracetest.SpanRecorder
+	var tracerProvider *sdktrace.TracerProvider
+
+	BeforeEach(func() {
+		recorder = tracetest.NewSpanRecorder()
+		tracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	})
+
+	It("should record a span per phase with a child span per kpt command", func() {
+		provider := app.NewProvider(NewMockClient(), newFakeFileSystem(), NewMockCluster().newCluster)
+
+		Expect(provider.Join(context.Background(), &app.NephioRunnerOptions{
+			SkipVerify: true, NephioRepoURI: "http://gitea/nephio-internal/packages.git",
+			TracerProvider: tracerProvider,
+		})).To(Succeed())
+
+		spans := recorder.Ended()
+		names := []string{}
+		for _, span := range spans {
+			names = append(names, span.Name())
+		}
+		Expect(names).To(Equal([]string{
+			"kpt pkg get", "kpt fn eval", "kpt fn render", "kpt live init", "kpt live apply", "nephioadm join",
+		}))
+
+		phase := spans[len(spans)-1]
+		for _, span := range spans[:len(spans)-1] {
+			Expect(span.Parent().SpanID()).To(Equal(phase.SpanContext().SpanID()))
+		}
+
+		Expect(spans[2].Attributes()).To(ContainElements(
+			tracing.PackagePathKey.String("/opt/nephio/configsync"),
+			tracing.PackageRepoKey.String("http://gitea/nephio-internal/packages.git"),
+			tracing.PackageNameKey.String("nephio-configsync"),
+			attribute.Int(string(tracing.ExitCodeKey), 0),
+		))
+	})
+})
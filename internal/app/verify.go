@@ -0,0 +1,181 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/electrocucaracha/nephioadm/internal/k8s"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	PhaseInit = "init"
+	PhaseJoin = "join"
+
+	DefaultVerifyTimeout = 5 * time.Minute
+	verifyInterval       = 5 * time.Second
+)
+
+var (
+	deploymentGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	crdGVR        = schema.GroupVersionResource{
+		Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions",
+	}
+	apiServiceGVR = schema.GroupVersionResource{
+		Group: "apiregistration.k8s.io", Version: "v1", Resource: "apiservices",
+	}
+)
+
+// ReadinessCheck waits for a condition of a Kubernetes resource to be true.
+type ReadinessCheck struct {
+	Name      string
+	GVR       schema.GroupVersionResource
+	Namespace string
+	Resource  string
+	Condition string
+}
+
+// CheckResult reports the outcome of a readiness check.
+type CheckResult struct {
+	Name     string
+	Ready    bool
+	Message  string
+	Duration time.Duration
+}
+
+type VerifyOptions struct {
+	Cluster k8s.ClusterOptions
+	Phase   string
+	Timeout time.Duration
+}
+
+// phaseChecks lists the readiness checks of the components installed on each phase.
+var phaseChecks = map[string][]ReadinessCheck{
+	PhaseInit: {
+		{
+			Name: "Nephio system controller", GVR: deploymentGVR,
+			Namespace: "nephio-system", Resource: "package-deployment-controller", Condition: "Available",
+		},
+		{
+			Name: "Nephio WebUI", GVR: deploymentGVR,
+			Namespace: "nephio-webui", Resource: "nephio-webui", Condition: "Available",
+		},
+		{
+			Name: "Porch API", GVR: apiServiceGVR,
+			Resource: "v1alpha1.porch.kpt.dev", Condition: "Available",
+		},
+	},
+	PhaseJoin: {
+		{
+			Name: "ConfigSync RepoSync API", GVR: crdGVR,
+			Resource: "reposyncs.configsync.gke.io", Condition: "Established",
+		},
+	},
+}
+
+// conditionStatus returns whether the condition of the resource provided is true and its message.
+func conditionStatus(resource *unstructured.Unstructured, conditionType string) (bool, string) {
+	conditions, _, _ := unstructured.NestedSlice(resource.Object, "status", "conditions")
+
+	for _, item := range conditions {
+		condition, ok := item.(map[string]interface{})
+		if !ok || condition["type"] != conditionType {
+			continue
+		}
+
+		message, _ := condition["message"].(string)
+
+		return condition["status"] == "True", message
+	}
+
+	return false, fmt.Sprintf("the %s condition isn't reported yet", conditionType)
+}
+
+func (c ReadinessCheck) run(ctx context.Context, cluster k8s.ClusterClient, timeout time.Duration) CheckResult {
+	result := CheckResult{Name: c.Name}
+	start := time.Now()
+
+	err := wait.PollImmediateWithContext(ctx, verifyInterval, timeout, func(ctx context.Context) (bool, error) {
+		resource, err := cluster.GetResource(ctx, c.GVR, c.Namespace, c.Resource)
+		if err != nil {
+			result.Message = err.Error()
+
+			return false, nil
+		}
+
+		result.Ready, result.Message = conditionStatus(resource, c.Condition)
+
+		return result.Ready, nil
+	})
+	if err != nil && len(result.Message) == 0 {
+		result.Message = err.Error()
+	}
+
+	result.Duration = time.Since(start).Round(time.Second)
+
+	return result
+}
+
+// verify runs the readiness checks of the phase provided concurrently.
+func verify(ctx context.Context, cluster k8s.ClusterClient, phase string, timeout time.Duration) ([]CheckResult, error) {
+	checks, ok := phaseChecks[phase]
+	if !ok {
+		return nil, errors.Errorf("unknown %q verification phase", phase)
+	}
+
+	if timeout == 0 {
+		timeout = DefaultVerifyTimeout
+	}
+
+	results := make([]CheckResult, len(checks))
+
+	var wg sync.WaitGroup
+
+	for i := range checks {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			results[i] = checks[i].run(ctx, cluster, timeout)
+		}(i)
+	}
+
+	wg.Wait()
+
+	failed := []string{}
+
+	for _, result := range results {
+		if !result.Ready {
+			failed = append(failed, result.Name)
+		}
+	}
+
+	if len(failed) != 0 {
+		return results, errors.Errorf("the %s verification failed: %s", phase, strings.Join(failed, ", "))
+	}
+
+	return results, nil
+}
@@ -0,0 +1,65 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/electrocucaracha/nephioadm/internal/app"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+var _ = Describe("Verification Engine", func() {
+	var provider *app.NephioProvider
+	var cluster *mockCluster
+
+	BeforeEach(func() {
+		cluster = NewMockCluster()
+		provider = app.NewProvider(NewMockClient(), filesys.MakeFsInMemory(), cluster.newCluster)
+	})
+
+	DescribeTable("verifies the installed components", func(notReady bool, phase string, expectedChecks int) {
+		cluster.NotReady = notReady
+		results, err := provider.Verify(context.Background(), &app.VerifyOptions{Phase: phase, Timeout: 10 * time.Millisecond})
+
+		if notReady {
+			Expect(err).To(HaveOccurred())
+		} else {
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Expect(results).To(HaveLen(expectedChecks))
+		for _, result := range results {
+			Expect(result.Ready).To(Equal(!notReady))
+			Expect(result.Message).To(Equal("test"))
+		}
+	},
+		Entry("when the management components are ready", false, app.PhaseInit, 3),
+		Entry("when the management components aren't ready", true, app.PhaseInit, 3),
+		Entry("when the workload components are ready", false, app.PhaseJoin, 1),
+		Entry("when the workload components aren't ready", true, app.PhaseJoin, 1),
+	)
+
+	It("should fail when the phase is unknown", func() {
+		_, err := provider.Verify(context.Background(), &app.VerifyOptions{Phase: "unknown"})
+
+		Expect(err).To(HaveOccurred())
+	})
+})
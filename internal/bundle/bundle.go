@@ -0,0 +1,255 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+	http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bundle
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ManifestFile describes the bundle content
+	ManifestFile = "bundle.yaml"
+	// ChecksumsFile lists the SHA-256 checksum of every bundle file, in the sha256sum format
+	ChecksumsFile = "checksums.txt"
+	// PackagesDir contains the packages as subdirectories named after their repository path
+	PackagesDir = "packages"
+)
+
+// Manifest describes the source of the bundled packages and the images they require.
+type Manifest struct {
+	Repository string    `json:"repository"`
+	Ref        string    `json:"ref,omitempty"`
+	Created    time.Time `json:"created"`
+	Packages   []Package `json:"packages"`
+	Images     Images    `json:"images"`
+}
+
+// Package is the lock data of a bundled package.
+type Package struct {
+	Component string `json:"component"`
+	Path      string `json:"path"`
+	// Commit is empty when the package wasn't fetched from a git repository
+	Commit string `json:"commit,omitempty"`
+}
+
+// Images are the images pulled during the installation of the bundled packages.
+type Images struct {
+	Functions  []string `json:"functions"`
+	Containers []string `json:"containers"`
+}
+
+// Write archives the manifest, the package directories indexed by their repository path and the
+// checksums of all of them into a gzip tarball.
+func Write(fSys filesys.FileSystem, file string, manifest *Manifest, packages map[string]string) error {
+	var buf bytes.Buffer
+
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	checksums := map[string]string{}
+
+	addFile := func(name string, content []byte) error {
+		sum := sha256.Sum256(content)
+		checksums[name] = hex.EncodeToString(sum[:])
+
+		if err := tarWriter.WriteHeader(&tar.Header{
+			Name: name, Mode: 0o644, Size: int64(len(content)), ModTime: manifest.Created, Typeflag: tar.TypeReg,
+		}); err != nil {
+			return err
+		}
+
+		_, err := tarWriter.Write(content)
+
+		return err
+	}
+
+	data, err := yaml.Marshal(manifest)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode the bundle manifest")
+	}
+
+	if err := addFile(ManifestFile, data); err != nil {
+		return errors.Wrap(err, "failed to archive the bundle manifest")
+	}
+
+	pkgPaths := make([]string, 0, len(packages))
+	for pkgPath := range packages {
+		pkgPaths = append(pkgPaths, pkgPath)
+	}
+
+	sort.Strings(pkgPaths)
+
+	for _, pkgPath := range pkgPaths {
+		dir := packages[pkgPath]
+
+		err := fSys.Walk(dir, func(file string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+
+			relPath, err := filepath.Rel(dir, file)
+			if err != nil {
+				return err
+			}
+
+			content, err := fSys.ReadFile(file)
+			if err != nil {
+				return err
+			}
+
+			return addFile(path.Join(PackagesDir, pkgPath, filepath.ToSlash(relPath)), content)
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to archive the %s package", pkgPath)
+		}
+	}
+
+	if err := addFile(ChecksumsFile, formatChecksums(checksums)); err != nil {
+		return errors.Wrap(err, "failed to archive the bundle checksums")
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return errors.Wrap(err, "failed to close the bundle tarball")
+	}
+
+	if err := gzipWriter.Close(); err != nil {
+		return errors.Wrap(err, "failed to compress the bundle tarball")
+	}
+
+	return errors.Wrapf(fSys.WriteFile(file, buf.Bytes()), "failed to write the %s bundle", file)
+}
+
+func formatChecksums(checksums map[string]string) []byte {
+	names := make([]string, 0, len(checksums))
+	for name := range checksums {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	for _, name := range names {
+		fmt.Fprintf(&buf, "%s  %s\n", checksums[name], name)
+	}
+
+	return buf.Bytes()
+}
+
+// Read verifies the checksums of the bundle files and returns its manifest.
+func Read(fSys filesys.FileSystem, file string) (*Manifest, error) {
+	data, err := fSys.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the %s bundle", file)
+	}
+
+	gzipReader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decompress the %s bundle", file)
+	}
+
+	sums := map[string]string{}
+	var manifestData, checksumsData []byte
+
+	tarReader := tar.NewReader(gzipReader)
+
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read the %s bundle", file)
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		content, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read the %s bundle file", header.Name)
+		}
+
+		switch header.Name {
+		case ManifestFile:
+			manifestData = content
+		case ChecksumsFile:
+			checksumsData = content
+
+			continue
+		}
+
+		sum := sha256.Sum256(content)
+		sums[header.Name] = hex.EncodeToString(sum[:])
+	}
+
+	if checksumsData == nil || manifestData == nil {
+		return nil, errors.Errorf("the %s bundle doesn't contain the %s and %s files", file, ManifestFile, ChecksumsFile)
+	}
+
+	if err := verifyChecksums(checksumsData, sums); err != nil {
+		return nil, errors.Wrapf(err, "failed to verify the %s bundle", file)
+	}
+
+	manifest := &Manifest{}
+	if err := yaml.Unmarshal(manifestData, manifest); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode the %s bundle manifest", file)
+	}
+
+	return manifest, nil
+}
+
+// verifyChecksums checks that the bundle files are the ones listed in the checksums file.
+func verifyChecksums(checksumsData []byte, sums map[string]string) error {
+	listed := map[string]bool{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(checksumsData))
+	for scanner.Scan() {
+		sum, name, ok := strings.Cut(scanner.Text(), "  ")
+		if !ok {
+			continue
+		}
+
+		if sums[name] != sum {
+			return errors.Errorf("the %s file checksum doesn't match", name)
+		}
+
+		listed[name] = true
+	}
+
+	for name := range sums {
+		if !listed[name] {
+			return errors.Errorf("the %s file isn't listed in the checksums", name)
+		}
+	}
+
+	return nil
+}
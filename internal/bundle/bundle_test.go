@@ -0,0 +1,127 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+	http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bundle_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/electrocucaracha/nephioadm/internal/bundle"
+	"github.com/electrocucaracha/nephioadm/internal/source"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const bundleFile = "/tmp/nephio-bundle.tar.gz"
+
+func appendComment(content []byte) []byte {
+	return append(content, []byte("# tampered\n")...)
+}
+
+// rewriteBundle applies the edit function provided to every file of the bundle.
+func rewriteBundle(fSys filesys.FileSystem, edit func(name string, content []byte) []byte) {
+	data, err := fSys.ReadFile(bundleFile)
+	Expect(err).NotTo(HaveOccurred())
+
+	gzipReader, err := gzip.NewReader(bytes.NewReader(data))
+	Expect(err).NotTo(HaveOccurred())
+
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	tarReader := tar.NewReader(gzipReader)
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		Expect(err).NotTo(HaveOccurred())
+
+		content, err := io.ReadAll(tarReader)
+		Expect(err).NotTo(HaveOccurred())
+
+		content = edit(header.Name, content)
+		header.Size = int64(len(content))
+		Expect(tarWriter.WriteHeader(header)).To(Succeed())
+		_, err = tarWriter.Write(content)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	Expect(tarWriter.Close()).To(Succeed())
+	Expect(gzipWriter.Close()).To(Succeed())
+	Expect(fSys.WriteFile(bundleFile, buf.Bytes())).To(Succeed())
+}
+
+var _ = Describe("Bundle", func() {
+	var fSys filesys.FileSystem
+	manifest := &bundle.Manifest{
+		Repository: "https://github.com/nephio-project/nephio-packages.git",
+		Ref:        "v1.0.1",
+		Created:    time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC),
+		Packages: []bundle.Package{
+			{Component: "system", Path: "nephio-system", Commit: "0123456789abcdef0123456789abcdef01234567"},
+		},
+		Images: bundle.Images{
+			Functions:  []string{"gcr.io/kpt-fn/search-replace:v0.2"},
+			Containers: []string{"docker.io/nephio/porch-server:v1.0.0"},
+		},
+	}
+
+	BeforeEach(func() {
+		fSys = filesys.MakeFsInMemory()
+		Expect(fSys.WriteFile("/work/system/Kptfile", []byte("kind: Kptfile\n"))).To(Succeed())
+		Expect(fSys.WriteFile("/work/system/porch/deployment.yaml", []byte("kind: Deployment\n"))).To(Succeed())
+		Expect(bundle.Write(fSys, bundleFile, manifest, map[string]string{"nephio-system": "/work/system"})).
+			To(Succeed())
+	})
+
+	It("should read the manifest of a verified bundle", func() {
+		Expect(bundle.Read(fSys, bundleFile)).To(Equal(manifest))
+	})
+
+	It("should be a source of the bundled packages", func() {
+		Expect(source.Fetch(fSys, bundleFile, "nephio-system", "/opt/nephio/system")).To(Succeed())
+		Expect(fSys.ReadFile("/opt/nephio/system/porch/deployment.yaml")).To(BeEquivalentTo("kind: Deployment\n"))
+	})
+
+	DescribeTable("tampered bundles", func(tampered string, edit func([]byte) []byte, expected string) {
+		rewriteBundle(fSys, func(name string, content []byte) []byte {
+			if name == tampered {
+				return edit(content)
+			}
+
+			return content
+		})
+
+		_, err := bundle.Read(fSys, bundleFile)
+
+		Expect(err).To(MatchError(ContainSubstring(expected)))
+	},
+		Entry("when a package file is modified", "packages/nephio-system/porch/deployment.yaml", appendComment,
+			"the packages/nephio-system/porch/deployment.yaml file checksum doesn't match"),
+		Entry("when the manifest is modified", "bundle.yaml", appendComment,
+			"the bundle.yaml file checksum doesn't match"),
+		Entry("when a file isn't listed", "checksums.txt", func(content []byte) []byte {
+			lines := strings.SplitAfter(string(content), "\n")
+
+			return []byte(strings.Join(lines[1:], ""))
+		}, "file isn't listed in the checksums"),
+	)
+})
@@ -0,0 +1,272 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+	http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/electrocucaracha/nephioadm/internal/k8s"
+	"github.com/electrocucaracha/nephioadm/internal/kpt"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const (
+	entryFile    = "entry.json"
+	lastUsedFile = "last-used"
+	packageDir   = "package"
+	// stagingDir holds the packages being fetched, they're moved into their entry once fetched
+	stagingDir = ".staging"
+)
+
+var stagingSeq uint64
+
+// Resolver resolves the git reference of a repository into the commit it points to, the
+// default branch is resolved when the reference is empty.
+type Resolver func(ctx context.Context, repoURI, ref string) (string, error)
+
+// Source identifies a package of a git repository.
+type Source struct {
+	RepoURI string
+	Path    string
+	// Ref is optional
+	Ref string
+}
+
+// Entry is a package stored in the cache.
+type Entry struct {
+	Key      string    `json:"key"`
+	RepoURI  string    `json:"repo"`
+	Path     string    `json:"path"`
+	Ref      string    `json:"ref,omitempty"`
+	Commit   string    `json:"commit"`
+	Fetched  time.Time `json:"fetched"`
+	LastUsed time.Time `json:"-"`
+	Size     int64     `json:"-"`
+}
+
+// Cache stores the fetched packages in a directory, addressed by their repository, path and
+// resolved commit, so they can be copied instead of fetched again.
+type Cache struct {
+	fSys    filesys.FileSystem
+	dir     string
+	resolve Resolver
+	now     func() time.Time
+}
+
+func New(fSys filesys.FileSystem, dir string, resolve Resolver) *Cache {
+	return &Cache{
+		fSys:    fSys,
+		dir:     dir,
+		resolve: resolve,
+		now:     time.Now,
+	}
+}
+
+// Key returns the content address of the package of the repository, path and commit provided.
+func Key(repoURI, path, commit string) string {
+	sum := sha256.Sum256([]byte(repoURI + "\x00" + path + "\x00" + commit))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *Cache) entryPath(key string, elem ...string) string {
+	return filepath.Join(append([]string{c.dir, key}, elem...)...)
+}
+
+// Fetch copies the package of the source provided into the dst directory. The fetch function
+// writes the package into the path provided when it isn't cached yet. It reports whether the
+// package was already cached.
+func (c *Cache) Fetch(ctx context.Context, src *Source, dst string, fetch func(path string) error) (bool, error) {
+	commit, err := c.resolve(ctx, src.RepoURI, src.Ref)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to resolve the %s package commit", src.Path)
+	}
+
+	key := Key(src.RepoURI, src.Path, commit)
+
+	cached := c.fSys.Exists(c.entryPath(key, entryFile))
+	if !cached {
+		if key, err = c.store(src, commit, fetch); err != nil {
+			return false, err
+		}
+	}
+
+	if err := k8s.CopyDir(c.fSys, c.entryPath(key, packageDir), dst); err != nil {
+		return false, errors.Wrapf(err, "failed to copy the %s cached package", src.Path)
+	}
+
+	// The cached package is named after the directory it was fetched into
+	if err := kpt.SetPackageName(c.fSys, dst); err != nil {
+		return false, err
+	}
+
+	if err := c.fSys.WriteFile(c.entryPath(key, lastUsedFile),
+		[]byte(c.now().UTC().Format(time.RFC3339))); err != nil {
+		return false, errors.Wrapf(err, "failed to update the %s cache entry", key)
+	}
+
+	return cached, nil
+}
+
+// store fetches the package into a staging directory and moves it into its cache entry. The
+// entry is addressed by the commit locked in the Kptfile, in case the reference has moved
+// since it was resolved.
+func (c *Cache) store(src *Source, commit string, fetch func(path string) error) (string, error) {
+	staging := filepath.Join(c.dir, stagingDir, fmt.Sprintf("%d-%d", os.Getpid(), atomic.AddUint64(&stagingSeq, 1)))
+	if err := c.fSys.MkdirAll(staging); err != nil {
+		return "", errors.Wrapf(err, "failed to create the %s staging directory", staging)
+	}
+	defer c.fSys.RemoveAll(staging)
+
+	if err := fetch(filepath.Join(staging, packageDir)); err != nil {
+		return "", err
+	}
+
+	if lockedCommit := kpt.LockedCommit(c.fSys, filepath.Join(staging, packageDir)); lockedCommit != "" {
+		commit = lockedCommit
+	}
+
+	entry := Entry{
+		Key:     Key(src.RepoURI, src.Path, commit),
+		RepoURI: src.RepoURI,
+		Path:    src.Path,
+		Ref:     src.Ref,
+		Commit:  commit,
+		Fetched: c.now().UTC(),
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encode the cache entry")
+	}
+
+	if err := c.fSys.WriteFile(filepath.Join(staging, entryFile), data); err != nil {
+		return "", errors.Wrapf(err, "failed to write the %s cache entry", entry.Key)
+	}
+
+	if err := k8s.MoveDir(c.fSys, staging, c.entryPath(entry.Key)); err != nil {
+		// Another installation may have stored the same package concurrently
+		if !c.fSys.Exists(c.entryPath(entry.Key, entryFile)) {
+			return "", errors.Wrapf(err, "failed to store the %s cache entry", entry.Key)
+		}
+	}
+
+	return entry.Key, nil
+}
+
+// List returns the cached packages, the most recently used first.
+func (c *Cache) List() ([]Entry, error) {
+	entries := []Entry{}
+	if !c.fSys.Exists(c.dir) {
+		return entries, nil
+	}
+
+	names, err := c.fSys.ReadDir(c.dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the %s cache directory", c.dir)
+	}
+
+	for _, name := range names {
+		if name == stagingDir || !c.fSys.Exists(c.entryPath(name, entryFile)) {
+			continue
+		}
+
+		entry, err := c.readEntry(name)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, *entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].LastUsed.After(entries[j].LastUsed)
+	})
+
+	return entries, nil
+}
+
+func (c *Cache) readEntry(key string) (*Entry, error) {
+	data, err := c.fSys.ReadFile(c.entryPath(key, entryFile))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the %s cache entry", key)
+	}
+
+	entry := &Entry{}
+	if err := json.Unmarshal(data, entry); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode the %s cache entry", key)
+	}
+
+	entry.LastUsed = entry.Fetched
+	if data, err := c.fSys.ReadFile(c.entryPath(key, lastUsedFile)); err == nil {
+		if lastUsed, err := time.Parse(time.RFC3339, string(data)); err == nil {
+			entry.LastUsed = lastUsed
+		}
+	}
+
+	err = c.fSys.Walk(c.entryPath(key, packageDir), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() {
+			entry.Size += info.Size()
+		}
+
+		return nil
+	})
+
+	return entry, errors.Wrapf(err, "failed to measure the %s cache entry", key)
+}
+
+// Prune removes the packages which haven't been used for longer than the duration provided,
+// all of them and the leftovers of interrupted fetches are removed when it isn't set.
+func (c *Cache) Prune(unusedFor time.Duration) ([]Entry, error) {
+	entries, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+
+	pruned := []Entry{}
+
+	for _, entry := range entries {
+		if unusedFor > 0 && c.now().Sub(entry.LastUsed) <= unusedFor {
+			continue
+		}
+
+		if err := c.fSys.RemoveAll(c.entryPath(entry.Key)); err != nil {
+			return pruned, errors.Wrapf(err, "failed to remove the %s cache entry", entry.Key)
+		}
+
+		pruned = append(pruned, entry)
+	}
+
+	if staging := filepath.Join(c.dir, stagingDir); unusedFor == 0 && c.fSys.Exists(staging) {
+		if err := c.fSys.RemoveAll(staging); err != nil {
+			return pruned, errors.Wrap(err, "failed to remove the staging directory")
+		}
+	}
+
+	return pruned, nil
+}
@@ -0,0 +1,149 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+	http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"time"
+
+	"github.com/electrocucaracha/nephioadm/internal/cache"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const (
+	repoURI     = "https://github.com/nephio-project/nephio-packages.git"
+	resolvedSHA = "1111111111111111111111111111111111111111"
+	lockedSHA   = "2222222222222222222222222222222222222222"
+	cacheDir    = "/var/cache/nephioadm"
+	packageName = "nephio-system"
+	// kpt names the package after the directory it's fetched into
+	kptfileLocked = `apiVersion: kpt.dev/v1
+kind: Kptfile
+metadata:
+  name: package
+upstreamLock:
+  type: git
+  git:
+    repo: https://github.com/nephio-project/nephio-packages
+    directory: /nephio-system
+    ref: main
+    commit: ` + lockedSHA + "\n"
+)
+
+var _ = Describe("Cache", func() {
+	var fSys filesys.FileSystem
+	var packageCache *cache.Cache
+	var fetches int
+	var fetchErr error
+	src := &cache.Source{RepoURI: repoURI, Path: packageName, Ref: "main"}
+
+	fetch := func(path string) error {
+		fetches++
+		if fetchErr != nil {
+			return fetchErr
+		}
+
+		Expect(fSys.WriteFile(filepath.Join(path, "Kptfile"), []byte(kptfileLocked))).To(Succeed())
+
+		return fSys.WriteFile(filepath.Join(path, "deployment.yaml"), []byte("kind: Deployment\n"))
+	}
+
+	BeforeEach(func() {
+		fSys = filesys.MakeFsInMemory()
+		fetches = 0
+		fetchErr = nil
+		packageCache = cache.New(fSys, cacheDir, func(ctx context.Context, repo, ref string) (string, error) {
+			return resolvedSHA, nil
+		})
+	})
+
+	It("should fetch the package only once", func() {
+		cached, err := packageCache.Fetch(context.Background(), src, "/opt/nephio/mgmt/system", fetch)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cached).To(BeFalse())
+
+		cached, err = packageCache.Fetch(context.Background(), src, "/opt/nephio/edge/system", fetch)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cached).To(BeFalse(), "the entry is addressed by the locked commit")
+
+		Expect(fetches).To(Equal(2))
+		Expect(fSys.ReadFile("/opt/nephio/edge/system/deployment.yaml")).To(BeEquivalentTo("kind: Deployment\n"))
+	})
+
+	It("should copy the cached package", func() {
+		packageCache = cache.New(fSys, cacheDir, func(ctx context.Context, repo, ref string) (string, error) {
+			return lockedSHA, nil
+		})
+
+		for _, dst := range []string{"/opt/nephio/mgmt/system", "/opt/nephio/edge/system"} {
+			_, err := packageCache.Fetch(context.Background(), src, dst, fetch)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fSys.ReadFile(filepath.Join(dst, "deployment.yaml"))).To(BeEquivalentTo("kind: Deployment\n"))
+			Expect(fSys.ReadFile(filepath.Join(dst, "Kptfile"))).To(ContainSubstring("name: system\n"))
+		}
+
+		Expect(fetches).To(Equal(1))
+
+		entries, err := packageCache.List()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Key).To(Equal(cache.Key(repoURI, packageName, lockedSHA)))
+		Expect(entries[0].Commit).To(Equal(lockedSHA))
+		Expect(entries[0].Ref).To(Equal("main"))
+		Expect(entries[0].Size).To(BeNumerically(">", 0))
+	})
+
+	It("should not store the packages which failed to be fetched", func() {
+		fetchErr = errors.New("connection reset by peer")
+
+		_, err := packageCache.Fetch(context.Background(), src, "/opt/nephio/mgmt/system", fetch)
+
+		Expect(err).To(MatchError("connection reset by peer"))
+		Expect(packageCache.List()).To(BeEmpty())
+		Expect(fSys.Exists("/opt/nephio/mgmt/system")).To(BeFalse())
+	})
+
+	It("should report the reference resolution failures", func() {
+		packageCache = cache.New(fSys, cacheDir, func(ctx context.Context, repo, ref string) (string, error) {
+			return "", errors.New("repository not found")
+		})
+
+		_, err := packageCache.Fetch(context.Background(), src, "/opt/nephio/mgmt/system", fetch)
+
+		Expect(err).To(MatchError("failed to resolve the nephio-system package commit: repository not found"))
+		Expect(fetches).To(BeZero())
+	})
+
+	DescribeTable("prune", func(unusedFor time.Duration, expected int) {
+		_, err := packageCache.Fetch(context.Background(), src, "/opt/nephio/mgmt/system", fetch)
+		Expect(err).NotTo(HaveOccurred())
+
+		pruned, err := packageCache.Prune(unusedFor)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pruned).To(HaveLen(expected))
+		Expect(packageCache.List()).To(HaveLen(1 - expected))
+	},
+		Entry("when all the entries are pruned", time.Duration(0), 1),
+		Entry("when the entries were recently used", time.Hour, 0),
+	)
+
+	It("should resolve the commits without listing the references", func() {
+		Expect(cache.LsRemote(context.Background(), "/nonexistent", lockedSHA)).To(Equal(lockedSHA))
+	})
+})
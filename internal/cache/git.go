@@ -0,0 +1,78 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+	http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var commitRegex = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// LsRemote resolves the git reference of the repository provided with git ls-remote.
+func LsRemote(ctx context.Context, repoURI, ref string) (string, error) {
+	if commitRegex.MatchString(ref) {
+		return ref, nil
+	}
+
+	if len(ref) == 0 {
+		ref = "HEAD"
+	}
+
+	var stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, "git", "ls-remote", repoURI, ref)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to list the %s references: %s", repoURI, strings.TrimSpace(stderr.String()))
+	}
+
+	return parseLsRemote(out, ref)
+}
+
+// parseLsRemote returns the commit of the reference listed by git ls-remote, the commit of
+// annotated tags is listed with the ^{} suffix.
+func parseLsRemote(out []byte, ref string) (string, error) {
+	commit := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+
+		if strings.HasSuffix(fields[1], "^{}") {
+			return fields[0], nil
+		}
+
+		if commit == "" {
+			commit = fields[0]
+		}
+	}
+
+	if commit == "" {
+		return "", errors.Errorf("the %s reference wasn't found", ref)
+	}
+
+	return commit, nil
+}
@@ -0,0 +1,196 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+	http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// ClusterOptions identifies the Kubernetes cluster to connect to. Empty
+// values fall back to the default kubeconfig loading rules and its current
+// context.
+type ClusterOptions struct {
+	Kubeconfig string
+	Context    string
+}
+
+type ClusterClient interface {
+	ApplyResource(context.Context, schema.GroupVersionResource, *unstructured.Unstructured) error
+	GetResource(context.Context, schema.GroupVersionResource, string, string) (*unstructured.Unstructured, error)
+	ListResources(context.Context, schema.GroupVersionResource, string, string) ([]unstructured.Unstructured, error)
+	ResourceFor(schema.GroupKind) (schema.GroupVersionResource, error)
+	GetPodLogs(context.Context, string, string, string) ([]byte, error)
+}
+
+type Cluster struct {
+	dynamic.Interface
+	Mapper    meta.RESTMapper
+	Clientset kubernetes.Interface
+}
+
+var _ ClusterClient = (*Cluster)(nil)
+
+func (o ClusterOptions) clientConfig() clientcmd.ClientConfig {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if len(o.Kubeconfig) != 0 {
+		rules.ExplicitPath = o.Kubeconfig
+	}
+
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: o.Context}
+
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides)
+}
+
+// CurrentContext returns the name of the kubeconfig context used to connect to the cluster.
+func (o ClusterOptions) CurrentContext() string {
+	if len(o.Context) != 0 {
+		return o.Context
+	}
+
+	config, err := o.clientConfig().RawConfig()
+	if err != nil {
+		return ""
+	}
+
+	return config.CurrentContext
+}
+
+// NewCluster creates a client for the Kubernetes cluster referenced by the options provided.
+func NewCluster(opts *ClusterOptions) (ClusterClient, error) {
+	config, err := opts.clientConfig().ClientConfig()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load the %q kubeconfig context", opts.Context)
+	}
+
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the Kubernetes client")
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the Kubernetes discovery client")
+	}
+
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient))
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the Kubernetes clientset")
+	}
+
+	return &Cluster{Interface: client, Mapper: mapper, Clientset: clientset}, nil
+}
+
+// ApplyResource creates the Kubernetes resource provided or updates it when it already exists.
+// The resources with a resourceVersion are only updated when they weren't changed since it, the
+// update fails with a conflict otherwise.
+func (c *Cluster) ApplyResource(ctx context.Context, gvr schema.GroupVersionResource,
+	resource *unstructured.Unstructured,
+) error {
+	client := c.Resource(gvr).Namespace(resource.GetNamespace())
+
+	if len(resource.GetResourceVersion()) != 0 {
+		_, err := client.Update(ctx, resource, metav1.UpdateOptions{})
+
+		return errors.Wrapf(err, "failed to update the %s %s resource", resource.GetKind(), resource.GetName())
+	}
+
+	current, err := client.Get(ctx, resource.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		if _, err := client.Create(ctx, resource, metav1.CreateOptions{}); err != nil {
+			return errors.Wrapf(err, "failed to create the %s %s resource", resource.GetKind(), resource.GetName())
+		}
+
+		return nil
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the %s %s resource", resource.GetKind(), resource.GetName())
+	}
+
+	resource.SetResourceVersion(current.GetResourceVersion())
+
+	if _, err := client.Update(ctx, resource, metav1.UpdateOptions{}); err != nil {
+		return errors.Wrapf(err, "failed to update the %s %s resource", resource.GetKind(), resource.GetName())
+	}
+
+	return nil
+}
+
+// GetResource retrieves the Kubernetes resource of the namespace provided.
+func (c *Cluster) GetResource(ctx context.Context, gvr schema.GroupVersionResource,
+	namespace, name string,
+) (*unstructured.Unstructured, error) {
+	resource, err := c.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the %s/%s %s resource", namespace, name, gvr.Resource)
+	}
+
+	return resource, nil
+}
+
+// ListResources retrieves the Kubernetes resources of the namespace provided matching the label selector.
+func (c *Cluster) ListResources(ctx context.Context, gvr schema.GroupVersionResource,
+	namespace, labelSelector string,
+) ([]unstructured.Unstructured, error) {
+	list, err := c.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the %s resources", gvr.Resource)
+	}
+
+	return list.Items, nil
+}
+
+// ResourceFor returns the preferred resource of the group kind provided.
+func (c *Cluster) ResourceFor(gk schema.GroupKind) (schema.GroupVersionResource, error) {
+	if c.Mapper == nil {
+		return schema.GroupVersionResource{}, errors.New("the cluster client has no REST mapper")
+	}
+
+	mapping, err := c.Mapper.RESTMapping(gk)
+	if err != nil {
+		return schema.GroupVersionResource{}, errors.Wrapf(err, "failed to find the %s resource", gk)
+	}
+
+	return mapping.Resource, nil
+}
+
+// GetPodLogs retrieves the logs of the container of the pod provided.
+func (c *Cluster) GetPodLogs(ctx context.Context, namespace, pod, container string) ([]byte, error) {
+	if c.Clientset == nil {
+		return nil, errors.New("the cluster client has no clientset")
+	}
+
+	logs, err := c.Clientset.CoreV1().Pods(namespace).GetLogs(pod, &corev1.PodLogOptions{Container: container}).DoRaw(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the logs of the %s/%s %s container", namespace, pod, container)
+	}
+
+	return logs, nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+	http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s_test
+
+import (
+	"context"
+
+	"github.com/electrocucaracha/nephioadm/internal/k8s"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func newConfigMap(name, value string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": "nephio-system",
+			"labels":    map[string]interface{}{"app": "nephio"},
+		},
+		"data": map[string]interface{}{"key": value},
+	}}
+}
+
+var _ = Describe("Kubernetes Cluster Client", func() {
+	configMapGVR := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	var cluster *k8s.Cluster
+
+	BeforeEach(func() {
+		cluster = &k8s.Cluster{Interface: fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{configMapGVR: "ConfigMapList"})}
+	})
+
+	It("should create and update resources", func() {
+		ctx := context.Background()
+
+		Expect(cluster.ApplyResource(ctx, configMapGVR, newConfigMap("test", "created"))).To(Succeed())
+		Expect(cluster.ApplyResource(ctx, configMapGVR, newConfigMap("test", "updated"))).To(Succeed())
+
+		items, err := cluster.ListResources(ctx, configMapGVR, "nephio-system", "app=nephio")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(items).To(HaveLen(1))
+
+		value, _, _ := unstructured.NestedString(items[0].Object, "data", "key")
+		Expect(value).To(Equal("updated"))
+	})
+
+	It("should only update the resources with a resource version", func() {
+		configMap := newConfigMap("test", "created")
+		configMap.SetResourceVersion("1")
+
+		Expect(cluster.ApplyResource(context.Background(), configMapGVR, configMap)).To(
+			MatchError(ContainSubstring("failed to update the ConfigMap test resource")))
+	})
+
+	It("should get the pod logs", func() {
+		cluster.Clientset = kubefake.NewSimpleClientset()
+
+		logs, err := cluster.GetPodLogs(context.Background(), "nephio-system", "controller", "manager")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(logs)).To(Equal("fake logs"))
+	})
+
+	It("should map kinds to their resources", func() {
+		mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{{Group: "apps", Version: "v1"}})
+		mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+		cluster.Mapper = mapper
+
+		gvr, err := cluster.ResourceFor(schema.GroupKind{Group: "apps", Kind: "Deployment"})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gvr).To(Equal(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}))
+	})
+})
@@ -0,0 +1,251 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+	http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// containerFields are the pod spec fields listing containers.
+var containerFields = map[string]bool{
+	"containers":          true,
+	"initContainers":      true,
+	"ephemeralContainers": true,
+}
+
+// ImageRule replaces the From prefix of the image references with To.
+type ImageRule struct {
+	From string
+	To   string
+}
+
+// ImageMirror rewrites the image references to be pulled from private registries, the rules take
+// precedence over the registry mirror.
+type ImageMirror struct {
+	// Registry replaces the registry of the image references which don't match any rule
+	Registry string
+	Rules    []ImageRule
+}
+
+// Enabled reports whether the image references have to be rewritten.
+func (m ImageMirror) Enabled() bool {
+	return len(m.Registry) != 0 || len(m.Rules) != 0
+}
+
+// Rewrite returns the mirrored reference of the image provided.
+func (m ImageMirror) Rewrite(image string) string {
+	for _, rule := range m.Rules {
+		if rest, ok := cutImagePrefix(image, rule.From); ok {
+			return rule.To + rest
+		}
+	}
+
+	if len(m.Registry) == 0 {
+		return image
+	}
+
+	return strings.TrimSuffix(m.Registry, "/") + "/" + imageRepository(image)
+}
+
+// cutImagePrefix removes the prefix of the image reference, as long as it isn't a partial
+// registry or repository name.
+func cutImagePrefix(image, prefix string) (string, bool) {
+	rest, ok := strings.CutPrefix(image, prefix)
+	if !ok || len(prefix) == 0 {
+		return "", false
+	}
+
+	if len(rest) == 0 || strings.HasSuffix(prefix, "/") || strings.ContainsAny(rest[:1], "/:@") {
+		return rest, true
+	}
+
+	return "", false
+}
+
+// imageRepository returns the image reference without its registry, the images without registry
+// are official Docker Hub images when they don't have a namespace.
+func imageRepository(image string) string {
+	registry, repository, found := strings.Cut(image, "/")
+	if !found {
+		return "library/" + image
+	}
+
+	if strings.ContainsAny(registry, ".:") || registry == "localhost" {
+		return repository
+	}
+
+	return image
+}
+
+// Images returns the container images of the package workloads, sorted and deduplicated.
+func (p *Package) Images() []string {
+	images := map[string]bool{}
+	for _, node := range p.nodes {
+		visitImages(node.YNode(), func(image *yaml.Node) {
+			images[image.Value] = true
+		})
+	}
+
+	return sortedKeys(images)
+}
+
+// RewriteImages replaces the container images of the package workloads with the references
+// returned by the rewrite function, and reports the number of rewritten images.
+func (p *Package) RewriteImages(rewrite func(string) string) (int, error) {
+	count := 0
+
+	for _, node := range p.nodes {
+		edited := false
+
+		visitImages(node.YNode(), func(image *yaml.Node) {
+			if value := rewrite(image.Value); value != image.Value {
+				image.Value = value
+				edited = true
+				count++
+			}
+		})
+
+		if edited {
+			if err := p.markEdited(node); err != nil {
+				return count, err
+			}
+		}
+	}
+
+	return count, nil
+}
+
+// visitImages visits the node recursively and calls the visit function with the image of every
+// container it lists.
+func visitImages(node *yaml.Node, visit func(*yaml.Node)) {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			visitImages(child, visit)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if containerFields[key.Value] && value.Kind == yaml.SequenceNode {
+				for _, container := range value.Content {
+					visitImage(container, visit)
+				}
+			}
+
+			visitImages(value, visit)
+		}
+	}
+}
+
+// visitImage calls the visit function with the image field of the node, when it's defined.
+func visitImage(node *yaml.Node, visit func(*yaml.Node)) {
+	if image := yaml.NewRNode(node).Field("image"); image != nil && len(image.Value.YNode().Value) != 0 {
+		visit(image.Value.YNode())
+	}
+}
+
+// FunctionImages returns the images of the kpt functions declared in the Kptfile pipelines of
+// the package directory and its subpackages, sorted and deduplicated.
+func FunctionImages(fSys filesys.FileSystem, path string) ([]string, error) {
+	images := map[string]bool{}
+
+	err := editFunctionImages(fSys, path, func(image *yaml.Node) bool {
+		images[image.Value] = true
+
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sortedKeys(images), nil
+}
+
+// RewriteFunctionImages replaces the images of the kpt functions declared in the Kptfile
+// pipelines of the package directory with the references returned by the rewrite function.
+func RewriteFunctionImages(fSys filesys.FileSystem, path string, rewrite func(string) string) error {
+	return editFunctionImages(fSys, path, func(image *yaml.Node) bool {
+		value := rewrite(image.Value)
+		edited := value != image.Value
+		image.Value = value
+
+		return edited
+	})
+}
+
+// editFunctionImages calls the edit function with the image of every kpt function declared in
+// the Kptfiles of the package directory, the Kptfiles are written when any image is edited.
+func editFunctionImages(fSys filesys.FileSystem, path string, edit func(*yaml.Node) bool) error {
+	err := fSys.Walk(path, func(file string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || filepath.Base(file) != "Kptfile" {
+			return err
+		}
+
+		data, err := fSys.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		kptfile, err := yaml.Parse(string(data))
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse the %s Kptfile", file)
+		}
+
+		edited := false
+
+		for _, field := range []string{"mutators", "validators"} {
+			functions, err := kptfile.Pipe(yaml.Lookup("pipeline", field))
+			if err != nil || functions == nil {
+				continue
+			}
+
+			for _, function := range functions.YNode().Content {
+				visitImage(function, func(image *yaml.Node) {
+					edited = edit(image) || edited
+				})
+			}
+		}
+
+		if !edited {
+			return nil
+		}
+
+		content, err := kptfile.String()
+		if err != nil {
+			return errors.Wrapf(err, "failed to encode the %s Kptfile", file)
+		}
+
+		return fSys.WriteFile(file, []byte(content))
+	})
+
+	return errors.Wrapf(err, "failed to read the %s package functions", path)
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
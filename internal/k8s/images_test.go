@@ -0,0 +1,136 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+	http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s_test
+
+import (
+	"github.com/electrocucaracha/nephioadm/internal/k8s"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const (
+	workloads = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: porch-server
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: docker.io/library/busybox:1.36
+      containers:
+      - name: porch-server
+        image: docker.io/nephio/porch-server:v1.0.0
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: cleanup
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: cleanup
+            image: docker.io/library/busybox:1.36
+`
+	pipelineKptfile = `apiVersion: kpt.dev/v1
+kind: Kptfile
+metadata:
+  name: nephio-system
+pipeline:
+  mutators:
+  - image: gcr.io/kpt-fn/set-namespace:v0.4.1
+    configMap:
+      namespace: nephio-system
+  validators:
+  - image: gcr.io/kpt-fn/kubeval:v0.3
+`
+)
+
+var _ = Describe("Images", func() {
+	var fSys filesys.FileSystem
+
+	BeforeEach(func() {
+		fSys = filesys.MakeFsInMemory()
+		Expect(fSys.WriteFile("/opt/nephio/system/workloads.yaml", []byte(workloads))).To(Succeed())
+		Expect(fSys.WriteFile("/opt/nephio/system/Kptfile", []byte(pipelineKptfile))).To(Succeed())
+		Expect(fSys.WriteFile("/opt/nephio/system/porch/Kptfile", []byte(pipelineKptfile))).To(Succeed())
+	})
+
+	It("should list the container images of the package workloads", func() {
+		pkg, err := k8s.ReadPackage(fSys, "/opt/nephio/system")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(pkg.Images()).To(Equal([]string{
+			"docker.io/library/busybox:1.36",
+			"docker.io/nephio/porch-server:v1.0.0",
+		}))
+	})
+
+	It("should list the function images of the package pipelines", func() {
+		Expect(k8s.FunctionImages(fSys, "/opt/nephio/system")).To(Equal([]string{
+			"gcr.io/kpt-fn/kubeval:v0.3",
+			"gcr.io/kpt-fn/set-namespace:v0.4.1",
+		}))
+	})
+
+	It("should rewrite the images of the package workloads and functions", func() {
+		mirror := k8s.ImageMirror{Registry: "registry.local:5000"}
+		pkg, err := k8s.ReadPackage(fSys, "/opt/nephio/system")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(pkg.RewriteImages(mirror.Rewrite)).To(Equal(3))
+		Expect(pkg.Write()).To(Succeed())
+		Expect(k8s.RewriteFunctionImages(fSys, "/opt/nephio/system", mirror.Rewrite)).To(Succeed())
+
+		pkg, err = k8s.ReadPackage(fSys, "/opt/nephio/system")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pkg.Images()).To(Equal([]string{
+			"registry.local:5000/library/busybox:1.36",
+			"registry.local:5000/nephio/porch-server:v1.0.0",
+		}))
+		Expect(k8s.FunctionImages(fSys, "/opt/nephio/system")).To(Equal([]string{
+			"registry.local:5000/kpt-fn/kubeval:v0.3",
+			"registry.local:5000/kpt-fn/set-namespace:v0.4.1",
+		}))
+	})
+
+	DescribeTable("image mirror rewriting", func(image, expected string) {
+		mirror := k8s.ImageMirror{
+			Registry: "registry.local:5000",
+			Rules: []k8s.ImageRule{
+				{From: "gcr.io/kpt-fn", To: "registry.local:5000/kpt"},
+				{From: "docker.io/nephio/", To: "harbor.local/nephio-"},
+			},
+		}
+
+		Expect(mirror.Rewrite(image)).To(Equal(expected))
+	},
+		Entry("when a rule matches", "gcr.io/kpt-fn/search-replace:v0.2", "registry.local:5000/kpt/search-replace:v0.2"),
+		Entry("when a rule prefix ends with a slash", "docker.io/nephio/porch-server:v1.0.0",
+			"harbor.local/nephio-porch-server:v1.0.0"),
+		Entry("when a rule matches partially a repository", "gcr.io/kpt-fn-contrib/kubeval",
+			"registry.local:5000/kpt-fn-contrib/kubeval"),
+		Entry("when the image has a registry", "quay.io/jetstack/cert-manager:v1.11",
+			"registry.local:5000/jetstack/cert-manager:v1.11"),
+		Entry("when the image has a namespace", "nephio/porch-server:v1.0.0",
+			"registry.local:5000/nephio/porch-server:v1.0.0"),
+		Entry("when the image is an official one", "busybox@sha256:abc", "registry.local:5000/library/busybox@sha256:abc"),
+		Entry("when the registry has a port", "localhost:5001/nephio/webui", "registry.local:5000/nephio/webui"),
+	)
+})
@@ -0,0 +1,332 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+	http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// jsonOperation is a JSON6902 operation applied on the YAML nodes of a resource, so the
+// comments and the order of the fields which aren't patched are kept.
+type jsonOperation struct {
+	Op    string
+	Path  string
+	From  string
+	Value *yaml.RNode
+}
+
+// decodeJSONOperations decodes the list of JSON6902 operations in YAML or JSON provided.
+func decodeJSONOperations(patch string) ([]jsonOperation, error) {
+	node, err := yaml.Parse(patch)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the JSON6902 operations")
+	}
+
+	elements, err := node.Elements()
+	if err != nil {
+		return nil, errors.Wrap(err, "the JSON6902 operations must be a list")
+	}
+
+	operations := make([]jsonOperation, 0, len(elements))
+
+	for _, element := range elements {
+		operation := jsonOperation{}
+		operation.Op, _ = element.GetString("op")
+		operation.Path, _ = element.GetString("path")
+		operation.From, _ = element.GetString("from")
+
+		if value := element.Field("value"); value != nil {
+			operation.Value = value.Value
+		}
+
+		if err := operation.validate(); err != nil {
+			return nil, err
+		}
+
+		operations = append(operations, operation)
+	}
+
+	return operations, nil
+}
+
+func (o jsonOperation) validate() error {
+	switch o.Op {
+	case "add", "replace", "test":
+		if o.Value == nil {
+			return errors.Errorf("the %s operation of the %q path requires a value", o.Op, o.Path)
+		}
+	case "move", "copy":
+		if _, err := pointerTokens(o.From); err != nil {
+			return err
+		}
+	case "remove":
+	default:
+		return errors.Errorf("unsupported %q JSON6902 operation", o.Op)
+	}
+
+	_, err := pointerTokens(o.Path)
+
+	return err
+}
+
+// pointerTokens splits the JSON pointer provided into its unescaped reference tokens.
+func pointerTokens(pointer string) ([]string, error) {
+	if len(pointer) == 0 {
+		return []string{}, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, errors.Errorf("the %q JSON pointer must start with a slash", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(tokens[i], "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+// sequenceIndex returns the element index referenced by the token provided, the end of the
+// sequence is only accepted when the element is added.
+func sequenceIndex(token string, length int, add bool) (int, error) {
+	if add && token == "-" {
+		return length, nil
+	}
+
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > length || (!add && index == length) {
+		return 0, errors.Errorf("the %q index is out of the bounds of the list", token)
+	}
+
+	return index, nil
+}
+
+// lookupNode returns the node referenced by the reference tokens provided.
+func lookupNode(node *yaml.Node, tokens []string) (*yaml.Node, error) {
+	for _, token := range tokens {
+		switch node.Kind {
+		case yaml.MappingNode:
+			next := mappingValue(node, token)
+			if next == nil {
+				return nil, errors.Errorf("the %q field doesn't exist", token)
+			}
+
+			node = next
+		case yaml.SequenceNode:
+			index, err := sequenceIndex(token, len(node.Content), false)
+			if err != nil {
+				return nil, err
+			}
+
+			node = node.Content[index]
+		default:
+			return nil, errors.Errorf("the %q field doesn't have a parent object or list", token)
+		}
+	}
+
+	return node, nil
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// addNode adds the value to the node referenced by the pointer, the fields which already exist
+// are replaced in place.
+func addNode(root *yaml.Node, pointer string, value *yaml.Node) error {
+	tokens, _ := pointerTokens(pointer)
+	if len(tokens) == 0 {
+		*root = *value
+
+		return nil
+	}
+
+	parent, err := lookupNode(root, tokens[:len(tokens)-1])
+	if err != nil {
+		return err
+	}
+
+	token := tokens[len(tokens)-1]
+
+	switch parent.Kind {
+	case yaml.MappingNode:
+		if current := mappingValue(parent, token); current != nil {
+			setValue(current, value)
+
+			return nil
+		}
+
+		parent.Content = append(parent.Content, yaml.NewStringRNode(token).YNode(), value)
+	case yaml.SequenceNode:
+		index, err := sequenceIndex(token, len(parent.Content), true)
+		if err != nil {
+			return err
+		}
+
+		parent.Content = append(parent.Content[:index], append([]*yaml.Node{value}, parent.Content[index:]...)...)
+	default:
+		return errors.Errorf("the %q path doesn't have a parent object or list", pointer)
+	}
+
+	return nil
+}
+
+// removeNode removes the node referenced by the pointer and returns it.
+func removeNode(root *yaml.Node, pointer string) (*yaml.Node, error) {
+	tokens, _ := pointerTokens(pointer)
+	if len(tokens) == 0 {
+		return nil, errors.New("the whole resource can't be removed")
+	}
+
+	parent, err := lookupNode(root, tokens[:len(tokens)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	token := tokens[len(tokens)-1]
+
+	switch parent.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(parent.Content); i += 2 {
+			if parent.Content[i].Value == token {
+				removed := parent.Content[i+1]
+				parent.Content = append(parent.Content[:i], parent.Content[i+2:]...)
+
+				return removed, nil
+			}
+		}
+
+		return nil, errors.Errorf("the %q field doesn't exist", token)
+	case yaml.SequenceNode:
+		index, err := sequenceIndex(token, len(parent.Content), false)
+		if err != nil {
+			return nil, err
+		}
+
+		removed := parent.Content[index]
+		parent.Content = append(parent.Content[:index], parent.Content[index+1:]...)
+
+		return removed, nil
+	default:
+		return nil, errors.Errorf("the %q path doesn't have a parent object or list", pointer)
+	}
+}
+
+// setValue replaces the content of the node provided, keeping its comments when the value
+// doesn't have its own.
+func setValue(node, value *yaml.Node) {
+	headComment, lineComment, footComment := node.HeadComment, node.LineComment, node.FootComment
+	*node = *value
+
+	if len(node.HeadComment)+len(node.LineComment)+len(node.FootComment) == 0 {
+		node.HeadComment, node.LineComment, node.FootComment = headComment, lineComment, footComment
+	}
+}
+
+// equalNodes reports whether the nodes provided have the same JSON value.
+func equalNodes(a, b *yaml.Node) bool {
+	var aValue, bValue interface{}
+	if err := a.Decode(&aValue); err != nil {
+		return false
+	}
+
+	if err := b.Decode(&bValue); err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(aValue, bValue)
+}
+
+func (o jsonOperation) apply(root *yaml.Node) error {
+	switch o.Op {
+	case "add":
+		return addNode(root, o.Path, o.Value.Copy().YNode())
+	case "remove":
+		_, err := removeNode(root, o.Path)
+
+		return err
+	case "replace":
+		tokens, _ := pointerTokens(o.Path)
+
+		current, err := lookupNode(root, tokens)
+		if err != nil {
+			return err
+		}
+
+		setValue(current, o.Value.Copy().YNode())
+
+		return nil
+	case "move":
+		if strings.HasPrefix(o.Path, o.From+"/") {
+			return errors.Errorf("the %q path can't be moved into its own child", o.From)
+		}
+
+		value, err := removeNode(root, o.From)
+		if err != nil {
+			return err
+		}
+
+		return addNode(root, o.Path, value)
+	case "copy":
+		tokens, _ := pointerTokens(o.From)
+
+		value, err := lookupNode(root, tokens)
+		if err != nil {
+			return err
+		}
+
+		return addNode(root, o.Path, yaml.CopyYNode(value))
+	case "test":
+		tokens, _ := pointerTokens(o.Path)
+
+		current, err := lookupNode(root, tokens)
+		if err != nil {
+			return err
+		}
+
+		if !equalNodes(current, o.Value.YNode()) {
+			return errors.Errorf("the %q path doesn't have the tested value", o.Path)
+		}
+	}
+
+	return nil
+}
+
+// applyJSONOperations applies the operations provided in order, the node isn't changed when one
+// of them fails.
+func applyJSONOperations(node *yaml.RNode, operations []jsonOperation) error {
+	result := node.Copy()
+
+	for _, operation := range operations {
+		if err := operation.apply(result.YNode()); err != nil {
+			return errors.Wrapf(err, "failed to %s the %q path", operation.Op, operation.Path)
+		}
+	}
+
+	node.SetYNode(result.YNode())
+
+	return nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+	http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const json6902Resource = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: nephio-webui-config
+  annotations:
+    example.com/owner: nephio
+data:
+  a~b: tilde
+  a~1b: escaped
+ports:
+- 80
+- 443
+`
+
+var _ = Describe("JSON6902 operations", func() {
+	apply := func(operations string) (string, error) {
+		node, err := yaml.Parse(json6902Resource)
+		Expect(err).NotTo(HaveOccurred())
+
+		decoded, err := decodeJSONOperations(operations)
+		if err != nil {
+			return "", err
+		}
+
+		if err := applyJSONOperations(node, decoded); err != nil {
+			return "", err
+		}
+
+		return node.MustString(), nil
+	}
+
+	DescribeTable("resolves the JSON pointers", func(operations, expected string) {
+		result, err := apply(operations)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(ContainSubstring(expected))
+	},
+		Entry("when a slash is escaped", `- {op: replace, path: /metadata/annotations/example.com~1owner,
+			value: edge}`, "example.com/owner: edge"),
+		Entry("when a tilde is escaped", `- {op: replace, path: /data/a~0b, value: replaced}`,
+			"a~b: replaced\n  a~1b: escaped"),
+		Entry("when an escaped tilde precedes a one", `- {op: replace, path: /data/a~01b, value: replaced}`,
+			"a~b: tilde\n  a~1b: replaced"),
+		Entry("when an element is appended with a dash", `- {op: add, path: /ports/-, value: 8080}`,
+			"ports:\n- 80\n- 443\n- 8080\n"),
+		Entry("when an element is inserted at the end index", `- {op: add, path: /ports/2, value: 8080}`,
+			"ports:\n- 80\n- 443\n- 8080\n"),
+		Entry("when an element is inserted at the first index", `- {op: add, path: /ports/0, value: 8080}`,
+			"ports:\n- 8080\n- 80\n- 443\n"),
+		Entry("when an element is copied to the end", `- {op: copy, from: /ports/0, path: /ports/-}`,
+			"ports:\n- 80\n- 443\n- 80\n"),
+	)
+
+	DescribeTable("rejects the invalid JSON pointers", func(operations, expected string) {
+		_, err := apply(operations)
+
+		Expect(err).To(MatchError(ContainSubstring(expected)))
+	},
+		Entry("when a dash is removed", `- {op: remove, path: /ports/-}`,
+			`the "-" index is out of the bounds of the list`),
+		Entry("when a dash is replaced", `- {op: replace, path: /ports/-, value: 8080}`,
+			`the "-" index is out of the bounds of the list`),
+		Entry("when the end index is replaced", `- {op: replace, path: /ports/2, value: 8080}`,
+			`the "2" index is out of the bounds of the list`),
+		Entry("when an element is added after the end", `- {op: add, path: /ports/3, value: 8080}`,
+			`the "3" index is out of the bounds of the list`),
+		Entry("when an unescaped slash is used", `- {op: remove, path: /metadata/annotations/example.com/owner}`,
+			`the "example.com" field doesn't exist`),
+		Entry("when the pointer doesn't start with a slash", `- {op: remove, path: data}`,
+			`the "data" JSON pointer must start with a slash`),
+		Entry("when a field is moved into its own child", `- {op: move, from: /data, path: /data/nested}`,
+			`the "/data" path can't be moved into its own child`),
+	)
+})
@@ -0,0 +1,168 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+	http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"bytes"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// ResourceID identifies the resources of a package. Empty fields match any value.
+type ResourceID struct {
+	schema.GroupVersionKind
+	Namespace string
+	Name      string
+}
+
+func (id ResourceID) String() string {
+	return id.GroupVersionKind.String() + " " + id.Namespace + "/" + id.Name
+}
+
+// Matches reports whether the resource provided is identified by the ID.
+func (id ResourceID) Matches(node *yaml.RNode) bool {
+	gvk := schema.FromAPIVersionAndKind(node.GetApiVersion(), node.GetKind())
+
+	return matches(id.Group, gvk.Group) && matches(id.Version, gvk.Version) &&
+		matches(id.Kind, gvk.Kind) && matches(id.Namespace, node.GetNamespace()) &&
+		matches(id.Name, node.GetName())
+}
+
+func matches(expected, value string) bool {
+	return len(expected) == 0 || expected == value
+}
+
+// Package indexes every resource of a package directory, keeping track of the
+// file and document where each one is stored.
+type Package struct {
+	fSys  filesys.FileSystem
+	path  string
+	nodes []*yaml.RNode
+	dirty map[string]bool
+}
+
+// ReadPackage reads all the resources of the package directory provided.
+func ReadPackage(fSys filesys.FileSystem, path string) (*Package, error) {
+	reader := kio.LocalPackageReader{
+		PackagePath:       path,
+		PreserveSeqIndent: true,
+		FileSystem:        filesys.FileSystemOrOnDisk{FileSystem: fSys},
+	}
+
+	nodes, err := reader.Read()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the %s package", path)
+	}
+
+	return &Package{fSys: fSys, path: path, nodes: nodes, dirty: map[string]bool{}}, nil
+}
+
+// Resources returns the package resources matching the identifier provided.
+func (p *Package) Resources(id ResourceID) []*yaml.RNode {
+	resources := []*yaml.RNode{}
+
+	for _, node := range p.nodes {
+		if id.Matches(node) {
+			resources = append(resources, node)
+		}
+	}
+
+	return resources
+}
+
+// EditResource applies the edit function provided to the only resource matching the identifier.
+func (p *Package) EditResource(id ResourceID, edit func(*yaml.RNode) error) error {
+	resources := p.Resources(id)
+
+	switch len(resources) {
+	case 0:
+		return errors.Errorf("the %s resource wasn't found in the %s package", id, p.path)
+	case 1:
+	default:
+		return errors.Errorf("multiple %s resources were found in the %s package", id, p.path)
+	}
+
+	if err := edit(resources[0]); err != nil {
+		return errors.Wrapf(err, "failed to edit the %s resource", id)
+	}
+
+	return p.markEdited(resources[0])
+}
+
+// markEdited flags the file of the resource provided to be written.
+func (p *Package) markEdited(node *yaml.RNode) error {
+	path, _, err := kioutil.GetFileAnnotations(node)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the file of the %s/%s resource", node.GetKind(), node.GetName())
+	}
+
+	p.dirty[path] = true
+
+	return nil
+}
+
+// Write serializes the edited resources back into the files and documents they were read from.
+func (p *Package) Write() error {
+	files := map[string][]*yaml.RNode{}
+
+	for _, node := range p.nodes {
+		path, _, err := kioutil.GetFileAnnotations(node)
+		if err != nil {
+			return errors.Wrap(err, "failed to get the file of the resource")
+		}
+
+		if p.dirty[path] {
+			files[path] = append(files[path], node)
+		}
+	}
+
+	for path, nodes := range files {
+		sort.SliceStable(nodes, func(i, j int) bool {
+			return documentIndex(nodes[i]) < documentIndex(nodes[j])
+		})
+
+		var out bytes.Buffer
+
+		writer := kio.ByteWriter{
+			Writer:           &out,
+			ClearAnnotations: []string{kioutil.PathAnnotation, kioutil.LegacyPathAnnotation},
+		}
+		if err := writer.Write(nodes); err != nil {
+			return errors.Wrap(err, "failed to marshal the Kubernetes resources")
+		}
+
+		if err := p.fSys.WriteFile(filepath.Join(p.path, path), out.Bytes()); err != nil {
+			return errors.Wrapf(err, "failed to write the Kubernetes resources into the %s resource file", path)
+		}
+
+		delete(p.dirty, path)
+	}
+
+	return nil
+}
+
+func documentIndex(node *yaml.RNode) int {
+	_, index, _ := kioutil.GetFileAnnotations(node)
+	value, _ := strconv.Atoi(index)
+
+	return value
+}
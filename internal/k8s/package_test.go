@@ -0,0 +1,147 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+	http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s_test
+
+import (
+	"github.com/electrocucaracha/nephioadm/internal/k8s"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	webUIResources = `# Nephio WebUI service
+apiVersion: v1
+kind: Service
+metadata: # kpt-merge: nephio-webui/nephio-webui
+  name: nephio-webui
+  namespace: nephio-webui # kpt-set: ${namespace}
+  annotations:
+    config.kubernetes.io/local-config: "false"
+spec:
+  ports:
+  - name: http
+    port: 7007
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: nephio-webui-config
+  namespace: nephio-webui
+data:
+  app-config.nephio.yaml: |
+    backend:
+      baseUrl: http://localhost:7007
+`
+	webUINamespace = `apiVersion: v1
+kind: Namespace
+metadata:
+  name: nephio-webui
+`
+)
+
+func newFakePackage() filesys.FileSystem {
+	fSys := filesys.MakeFsInMemory()
+	Expect(fSys.MkdirAll("/opt/nephio/webui")).To(Succeed())
+	Expect(fSys.WriteFile("/opt/nephio/webui/resources.yaml", []byte(webUIResources))).To(Succeed())
+	Expect(fSys.WriteFile("/opt/nephio/webui/namespace.yaml", []byte(webUINamespace))).To(Succeed())
+
+	return fSys
+}
+
+func setServiceType(node *yaml.RNode) error {
+	return node.PipeE(yaml.LookupCreate(yaml.MappingNode, "spec"),
+		yaml.SetField("type", yaml.NewStringRNode("NodePort")))
+}
+
+var _ = Describe("Kubernetes Package", func() {
+	serviceGVK := schema.GroupVersionKind{Version: "v1", Kind: "Service"}
+	var fSys filesys.FileSystem
+
+	BeforeEach(func() {
+		fSys = newFakePackage()
+	})
+
+	DescribeTable("looks up resources", func(id k8s.ResourceID, expected int) {
+		pkg, err := k8s.ReadPackage(fSys, "/opt/nephio/webui")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pkg.Resources(id)).To(HaveLen(expected))
+	},
+		Entry("when all resources are requested", k8s.ResourceID{}, 3),
+		Entry("when resources are requested by namespace", k8s.ResourceID{Namespace: "nephio-webui"}, 2),
+		Entry("when resources are requested by name", k8s.ResourceID{Name: "nephio-webui"}, 2),
+		Entry("when a resource is requested by GVK and name",
+			k8s.ResourceID{GroupVersionKind: serviceGVK, Name: "nephio-webui"}, 1),
+		Entry("when a non existing resource is requested",
+			k8s.ResourceID{GroupVersionKind: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}}, 0),
+	)
+
+	It("should write edits back to the file and document of the resource", func() {
+		pkg, err := k8s.ReadPackage(fSys, "/opt/nephio/webui")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(pkg.EditResource(k8s.ResourceID{GroupVersionKind: serviceGVK}, setServiceType)).To(Succeed())
+		Expect(pkg.Write()).To(Succeed())
+
+		data, err := fSys.ReadFile("/opt/nephio/webui/resources.yaml")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal(`# Nephio WebUI service
+apiVersion: v1
+kind: Service
+metadata: # kpt-merge: nephio-webui/nephio-webui
+  name: nephio-webui
+  namespace: nephio-webui # kpt-set: ${namespace}
+  annotations:
+    config.kubernetes.io/local-config: "false"
+spec:
+  ports:
+  - name: http
+    port: 7007
+  type: NodePort
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: nephio-webui-config
+  namespace: nephio-webui
+data:
+  app-config.nephio.yaml: |
+    backend:
+      baseUrl: http://localhost:7007
+`))
+
+		data, err = fSys.ReadFile("/opt/nephio/webui/namespace.yaml")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal(webUINamespace))
+	})
+
+	DescribeTable("fails to edit resources", func(id k8s.ResourceID) {
+		pkg, err := k8s.ReadPackage(fSys, "/opt/nephio/webui")
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pkg.EditResource(id, setServiceType)).NotTo(Succeed())
+	},
+		Entry("when the resource doesn't exist", k8s.ResourceID{Name: "non-existing"}),
+		Entry("when multiple resources match", k8s.ResourceID{Namespace: "nephio-webui"}),
+	)
+
+	It("should fail when the package doesn't exist", func() {
+		_, err := k8s.ReadPackage(fSys, "/opt/nephio/non-existing")
+
+		Expect(err).To(HaveOccurred())
+	})
+})
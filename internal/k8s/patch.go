@@ -0,0 +1,185 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+	http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"bytes"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+	"sigs.k8s.io/kustomize/kyaml/yaml/merge2"
+)
+
+// Patch is a strategic-merge or JSON6902 patch targeting package resources.
+type Patch struct {
+	Source string
+	Target ResourceID
+
+	strategicMerge *yaml.RNode
+	json6902       []jsonOperation
+}
+
+// Type returns the kind of patch.
+func (p Patch) Type() string {
+	if p.strategicMerge != nil {
+		return "strategic-merge"
+	}
+
+	return "json6902"
+}
+
+// patchTarget follows the kustomize patch target format.
+type patchTarget struct {
+	Group     string `yaml:"group,omitempty"`
+	Version   string `yaml:"version,omitempty"`
+	Kind      string `yaml:"kind,omitempty"`
+	Namespace string `yaml:"namespace,omitempty"`
+	Name      string `yaml:"name,omitempty"`
+}
+
+// json6902Patch follows the kustomize patches entry format, where patch contains the
+// list of JSON6902 operations in YAML or JSON.
+type json6902Patch struct {
+	Target *patchTarget `yaml:"target,omitempty"`
+	Patch  string       `yaml:"patch,omitempty"`
+}
+
+// ReadPatches reads the patches stored in the YAML files of the directory provided. Documents
+// with a target and patch fields are JSON6902 patches, any other resource is a strategic-merge
+// patch targeting the resource with its GVK, namespace and name.
+func ReadPatches(fSys filesys.FileSystem, path string) ([]Patch, error) {
+	files, err := fSys.Glob(filepath.Join(path, "*.yaml"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the patches of the %s directory", path)
+	}
+
+	ymlFiles, err := fSys.Glob(filepath.Join(path, "*.yml"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the patches of the %s directory", path)
+	}
+
+	files = append(files, ymlFiles...)
+	sort.Strings(files)
+
+	patches := []Patch{}
+
+	for _, file := range files {
+		data, err := fSys.ReadFile(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read the %s patch file", file)
+		}
+
+		nodes, err := (&kio.ByteReader{Reader: bytes.NewReader(data), OmitReaderAnnotations: true}).Read()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse the %s patch file", file)
+		}
+
+		for _, node := range nodes {
+			patch, err := newPatch(node)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid patch in the %s file", file)
+			}
+
+			patch.Source = filepath.Base(file)
+			patches = append(patches, *patch)
+		}
+	}
+
+	return patches, nil
+}
+
+func newPatch(node *yaml.RNode) (*Patch, error) {
+	if node.Field("target") == nil || node.Field("patch") == nil {
+		gvk := schema.FromAPIVersionAndKind(node.GetApiVersion(), node.GetKind())
+		if gvk.Empty() || len(node.GetName()) == 0 {
+			return nil, errors.New("strategic-merge patches require apiVersion, kind and metadata.name fields")
+		}
+
+		return &Patch{
+			Target:         ResourceID{GroupVersionKind: gvk, Namespace: node.GetNamespace(), Name: node.GetName()},
+			strategicMerge: node,
+		}, nil
+	}
+
+	var entry json6902Patch
+	if err := node.YNode().Decode(&entry); err != nil {
+		return nil, errors.Wrap(err, "failed to decode the JSON6902 patch")
+	}
+
+	if entry.Target == nil {
+		return nil, errors.New("JSON6902 patches require a target")
+	}
+
+	operations, err := decodeJSONOperations(entry.Patch)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode the JSON6902 operations")
+	}
+
+	return &Patch{
+		Target: ResourceID{
+			GroupVersionKind: schema.GroupVersionKind{
+				Group: entry.Target.Group, Version: entry.Target.Version, Kind: entry.Target.Kind,
+			},
+			Namespace: entry.Target.Namespace,
+			Name:      entry.Target.Name,
+		},
+		json6902: operations,
+	}, nil
+}
+
+func (p Patch) apply(node *yaml.RNode) error {
+	if p.strategicMerge != nil {
+		result, err := merge2.Merge(p.strategicMerge.Copy(), node, yaml.MergeOptions{})
+		if err != nil {
+			return errors.Wrap(err, "failed to merge the patch")
+		}
+
+		if result == nil {
+			return errors.New("deleting resources through patches is not supported")
+		}
+
+		node.SetYNode(result.YNode())
+
+		return nil
+	}
+
+	return errors.Wrap(applyJSONOperations(node, p.json6902), "failed to apply the JSON6902 operations")
+}
+
+// ApplyPatch applies the patch provided to every package resource matching its target and
+// returns the number of patched resources.
+func (p *Package) ApplyPatch(patch *Patch) (int, error) {
+	resources := p.Resources(patch.Target)
+	if len(resources) == 0 {
+		return 0, errors.Errorf("the %s patch target %s doesn't match any resource of the %s package",
+			patch.Source, patch.Target, p.path)
+	}
+
+	for _, resource := range resources {
+		if err := patch.apply(resource); err != nil {
+			return 0, errors.Wrapf(err, "failed to apply the %s patch", patch.Source)
+		}
+
+		if err := p.markEdited(resource); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(resources), nil
+}
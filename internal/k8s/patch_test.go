@@ -0,0 +1,177 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+	http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s_test
+
+import (
+	"github.com/electrocucaracha/nephioadm/internal/k8s"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const (
+	strategicMergePatch = `apiVersion: v1
+kind: Service
+metadata:
+  name: nephio-webui
+spec:
+  ports:
+  - name: http
+    port: 7007
+    nodePort: 30007
+`
+	json6902Patch = `target:
+  version: v1
+  kind: ConfigMap
+  name: nephio-webui-config
+patch: |-
+  - op: add
+    path: /data/log-level
+    value: debug
+`
+)
+
+var _ = Describe("Package Patches", func() {
+	var fSys filesys.FileSystem
+
+	BeforeEach(func() {
+		fSys = newFakePackage()
+		Expect(fSys.MkdirAll("/tmp/patches")).To(Succeed())
+	})
+
+	DescribeTable("applies patches", func(patch string, expected ...string) {
+		Expect(fSys.WriteFile("/tmp/patches/patch.yaml", []byte(patch))).To(Succeed())
+
+		patches, err := k8s.ReadPatches(fSys, "/tmp/patches")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(patches).To(HaveLen(1))
+
+		pkg, err := k8s.ReadPackage(fSys, "/opt/nephio/webui")
+		Expect(err).NotTo(HaveOccurred())
+
+		patched, err := pkg.ApplyPatch(&patches[0])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(patched).To(Equal(1))
+		Expect(pkg.Write()).To(Succeed())
+
+		data, err := fSys.ReadFile("/opt/nephio/webui/resources.yaml")
+		Expect(err).NotTo(HaveOccurred())
+		for _, value := range expected {
+			Expect(string(data)).To(ContainSubstring(value))
+		}
+	},
+		Entry("when a strategic-merge patch is provided", strategicMergePatch,
+			"# Nephio WebUI service", "namespace: nephio-webui # kpt-set: ${namespace}",
+			"  - name: http\n    port: 7007\n    nodePort: 30007\n"),
+		Entry("when a JSON6902 patch is provided", json6902Patch, "log-level: debug"),
+	)
+
+	It("should keep the comments and the field order of the JSON6902 patched resources", func() {
+		Expect(fSys.WriteFile("/tmp/patches/patch.yaml", []byte(`target:
+  kind: Service
+patch: |-
+  - op: test
+    path: /metadata/annotations/config.kubernetes.io~1local-config
+    value: "false"
+  - op: replace
+    path: /metadata/namespace
+    value: nephio-system
+  - op: add
+    path: /spec/ports/-
+    value:
+      name: https
+      port: 7443
+  - op: copy
+    from: /spec/ports/0/port
+    path: /spec/ports/0/targetPort
+  - op: move
+    from: /spec/ports/1/port
+    path: /spec/ports/1/targetPort
+`))).To(Succeed())
+
+		patches, err := k8s.ReadPatches(fSys, "/tmp/patches")
+		Expect(err).NotTo(HaveOccurred())
+
+		pkg, err := k8s.ReadPackage(fSys, "/opt/nephio/webui")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pkg.ApplyPatch(&patches[0])).To(Equal(1))
+		Expect(pkg.Write()).To(Succeed())
+
+		data, err := fSys.ReadFile("/opt/nephio/webui/resources.yaml")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(HavePrefix(`# Nephio WebUI service
+apiVersion: v1
+kind: Service
+metadata: # kpt-merge: nephio-webui/nephio-webui
+  name: nephio-webui
+  namespace: nephio-system # kpt-set: ${namespace}
+  annotations:
+    config.kubernetes.io/local-config: "false"
+spec:
+  ports:
+  - name: http
+    port: 7007
+    targetPort: 7007
+  - name: https
+    targetPort: 7443
+---
+`))
+	})
+
+	DescribeTable("fails to apply patches", func(patch string) {
+		Expect(fSys.WriteFile("/tmp/patches/patch.yaml", []byte(patch))).To(Succeed())
+
+		patches, err := k8s.ReadPatches(fSys, "/tmp/patches")
+		Expect(err).NotTo(HaveOccurred())
+
+		pkg, err := k8s.ReadPackage(fSys, "/opt/nephio/webui")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = pkg.ApplyPatch(&patches[0])
+		Expect(err).To(HaveOccurred())
+	},
+		Entry("when the strategic-merge patch target doesn't exist", `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nephio-webui
+spec:
+  replicas: 2
+`),
+		Entry("when the JSON6902 patch operation fails", `target:
+  kind: Service
+patch: |-
+  - op: remove
+    path: /spec/non-existing
+`),
+		Entry("when the JSON6902 patch test fails", `target:
+  kind: Service
+patch: |-
+  - op: test
+    path: /spec/ports/0/port
+    value: 8080
+`),
+	)
+
+	DescribeTable("fails to read invalid patches", func(patch string) {
+		Expect(fSys.WriteFile("/tmp/patches/patch.yaml", []byte(patch))).To(Succeed())
+
+		_, err := k8s.ReadPatches(fSys, "/tmp/patches")
+		Expect(err).To(HaveOccurred())
+	},
+		Entry("when the strategic-merge patch doesn't have a name", "apiVersion: v1\nkind: Service\n"),
+		Entry("when the JSON6902 patch doesn't have valid operations", "target:\n  kind: Service\npatch: invalid\n"),
+		Entry("when the JSON6902 operation isn't supported",
+			"target:\n  kind: Service\npatch: |-\n  - op: merge\n    path: /spec\n"),
+	)
+})
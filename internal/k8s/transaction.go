@@ -0,0 +1,187 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+	http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const tempFileSuffix = ".nephioadm.tmp"
+
+// renamer is implemented by file systems able to atomically replace files.
+type renamer interface {
+	Rename(oldpath, newpath string) error
+}
+
+// syncer is implemented by files able to flush their content to the storage device.
+type syncer interface {
+	Sync() error
+}
+
+type fsOnDisk struct {
+	filesys.FileSystem
+}
+
+// MakeFsOnDisk returns the operating system file system, which supports atomic file replacements.
+func MakeFsOnDisk() filesys.FileSystem {
+	return fsOnDisk{FileSystem: filesys.MakeFsOnDisk()}
+}
+
+func (fsOnDisk) Rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+// Transaction is a file system scoped to a package directory whose writes are atomic. The
+// package content is backed up when the transaction begins, so it can be rolled back to
+// that state if any of its mutations fails.
+type Transaction struct {
+	filesys.FileSystem
+	path       string
+	backupPath string
+}
+
+// BeginTransaction backs up the content of the package directory provided.
+func BeginTransaction(fSys filesys.FileSystem, path string) (*Transaction, error) {
+	t := &Transaction{
+		FileSystem: fSys,
+		path:       filepath.Clean(path),
+		backupPath: filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".backup"),
+	}
+
+	if err := fSys.RemoveAll(t.backupPath); err != nil {
+		return nil, errors.Wrapf(err, "failed to remove the stale %s backup", t.backupPath)
+	}
+
+	if err := CopyDir(fSys, t.path, t.backupPath); err != nil {
+		return nil, errors.Wrapf(err, "failed to back up the %s package", t.path)
+	}
+
+	return t, nil
+}
+
+// WriteFile writes the data through the package file system atomically.
+func (t *Transaction) WriteFile(path string, data []byte) error {
+	return WriteFile(t.FileSystem, path, data)
+}
+
+// Commit discards the backup of the package.
+func (t *Transaction) Commit() error {
+	return errors.Wrapf(t.RemoveAll(t.backupPath), "failed to remove the %s backup", t.backupPath)
+}
+
+// Rollback restores the package content to the state it had when the transaction began.
+func (t *Transaction) Rollback() error {
+	if err := t.RemoveAll(t.path); err != nil {
+		return errors.Wrapf(err, "failed to remove the %s package", t.path)
+	}
+
+	if err := CopyDir(t.FileSystem, t.backupPath, t.path); err != nil {
+		return errors.Wrapf(err, "failed to restore the %s package", t.path)
+	}
+
+	return t.Commit()
+}
+
+// MoveDir moves the src directory to dst, which is atomic on file systems able to rename files.
+func MoveDir(fSys filesys.FileSystem, src, dst string) error {
+	if fsRenamer, ok := fSys.(renamer); ok {
+		return fsRenamer.Rename(src, dst)
+	}
+
+	if fSys.Exists(dst) {
+		return errors.Errorf("the %s directory already exists", dst)
+	}
+
+	if err := CopyDir(fSys, src, dst); err != nil {
+		return err
+	}
+
+	return fSys.RemoveAll(src)
+}
+
+// CopyDir copies the content of the src directory into dst.
+func CopyDir(fSys filesys.FileSystem, src, dst string) error {
+	return fSys.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dst, relPath)
+		if info.IsDir() {
+			return fSys.MkdirAll(target)
+		}
+
+		data, err := fSys.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		return fSys.WriteFile(target, data)
+	})
+}
+
+// WriteFile writes the data into a temporary file, flushes it and renames it to the path provided,
+// so the file is never left partially written on file systems able to rename files.
+func WriteFile(fSys filesys.FileSystem, path string, data []byte) error {
+	fsRenamer, ok := fSys.(renamer)
+	if !ok {
+		return fSys.WriteFile(path, data)
+	}
+
+	tempPath := path + tempFileSuffix
+
+	file, err := fSys.Create(tempPath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create the %s temporary file", tempPath)
+	}
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		fSys.RemoveAll(tempPath)
+
+		return errors.Wrapf(err, "failed to write the %s temporary file", tempPath)
+	}
+
+	if fileSyncer, ok := file.(syncer); ok {
+		if err := fileSyncer.Sync(); err != nil {
+			file.Close()
+			fSys.RemoveAll(tempPath)
+
+			return errors.Wrapf(err, "failed to flush the %s temporary file", tempPath)
+		}
+	}
+
+	if err := file.Close(); err != nil {
+		fSys.RemoveAll(tempPath)
+
+		return errors.Wrapf(err, "failed to close the %s temporary file", tempPath)
+	}
+
+	if err := fsRenamer.Rename(tempPath, path); err != nil {
+		fSys.RemoveAll(tempPath)
+
+		return errors.Wrapf(err, "failed to replace the %s file", path)
+	}
+
+	return nil
+}
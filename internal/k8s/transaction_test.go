@@ -0,0 +1,76 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+	http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s_test
+
+import (
+	"path/filepath"
+
+	"github.com/electrocucaracha/nephioadm/internal/k8s"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+var _ = Describe("Package Transaction", func() {
+	var fSys filesys.FileSystem
+	var pkgPath, resourcesPath, backupPath string
+
+	DescribeTable("finishes transactions", func(onDisk, commit bool, expected string) {
+		basePath := "/opt/nephio"
+		fSys = filesys.MakeFsInMemory()
+
+		if onDisk {
+			basePath = GinkgoT().TempDir()
+			fSys = k8s.MakeFsOnDisk()
+		}
+
+		pkgPath = filepath.Join(basePath, "webui")
+		resourcesPath = filepath.Join(pkgPath, "resources.yaml")
+		backupPath = filepath.Join(basePath, ".webui.backup")
+
+		Expect(fSys.MkdirAll(pkgPath)).To(Succeed())
+		Expect(fSys.WriteFile(resourcesPath, []byte(webUIResources))).To(Succeed())
+
+		tx, err := k8s.BeginTransaction(fSys, pkgPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fSys.Exists(backupPath)).To(BeTrue())
+
+		Expect(tx.WriteFile(resourcesPath, []byte("edited"))).To(Succeed())
+		Expect(tx.WriteFile(filepath.Join(pkgPath, "new.yaml"), []byte("created"))).To(Succeed())
+		Expect(fSys.ReadFile(resourcesPath)).To(BeEquivalentTo("edited"))
+
+		if commit {
+			Expect(tx.Commit()).To(Succeed())
+			Expect(fSys.Exists(filepath.Join(pkgPath, "new.yaml"))).To(BeTrue())
+		} else {
+			Expect(tx.Rollback()).To(Succeed())
+			Expect(fSys.Exists(filepath.Join(pkgPath, "new.yaml"))).To(BeFalse())
+		}
+
+		Expect(fSys.ReadFile(resourcesPath)).To(BeEquivalentTo(expected))
+		Expect(fSys.Exists(backupPath)).To(BeFalse())
+		Expect(fSys.Exists(resourcesPath + ".nephioadm.tmp")).To(BeFalse())
+	},
+		Entry("when changes are committed on disk", true, true, "edited"),
+		Entry("when changes are rolled back on disk", true, false, webUIResources),
+		Entry("when changes are committed in memory", false, true, "edited"),
+		Entry("when changes are rolled back in memory", false, false, webUIResources),
+	)
+
+	It("should fail when the package doesn't exist", func() {
+		_, err := k8s.BeginTransaction(filesys.MakeFsInMemory(), "/opt/nephio/non-existing")
+
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -15,9 +15,16 @@ package kpt
 
 import (
 	"bytes"
-	"log"
-	"os"
+	"context"
+	"io"
 	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/electrocucaracha/nephioadm/internal/logging"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
 
 type Package struct {
@@ -56,98 +63,286 @@ func NewPackage(opts *PackageOptions) *Package {
 	return p
 }
 
+// CommandResult reports the execution of a kpt command.
+type CommandResult struct {
+	Args     []string
+	Duration time.Duration
+	// ExitCode is -1 when the command couldn't be executed
+	ExitCode int
+	Err      error
+}
+
+func (r CommandResult) String() string {
+	return "kpt " + strings.Join(r.Args, " ")
+}
+
+// Name returns the kpt subcommand, e.g. kpt fn render.
+func (r CommandResult) Name() string {
+	return commandName(r.Args)
+}
+
+// CommandOptions are the options of a single kpt operation. Every operation carries its own
+// options, so the packages of different components or clusters can be processed concurrently
+// with the same client.
+type CommandOptions struct {
+	// Path is the local directory of the package
+	Path string
+	// Logger reports the kpt command, the default logger is used when it isn't provided
+	Logger logr.Logger
+	// Observer is notified after every kpt command execution
+	Observer func(CommandResult)
+	// Kubeconfig and Context select the cluster of the live commands, the current context is used by default
+	Kubeconfig string
+	Context    string
+	// Retry are the retry policies of the operation types, the default ones are used when they aren't provided
+	Retry RetryPolicies
+	// ReconcileTimeout limits the wait of the live apply, kpt waits until it's interrupted when it isn't provided
+	ReconcileTimeout time.Duration
+}
+
+func (o *CommandOptions) logger() logr.Logger {
+	return logging.OrDefault(o.Logger)
+}
+
+// clusterArgs returns the flags selecting the cluster of the live commands.
+func (o *CommandOptions) clusterArgs() []string {
+	args := []string{}
+
+	if len(o.Kubeconfig) != 0 {
+		args = append(args, "--kubeconfig", o.Kubeconfig)
+	}
+
+	if len(o.Context) != 0 {
+		args = append(args, "--context", o.Context)
+	}
+
+	return args
+}
+
 type Client interface {
-	PkgGet(*Package)
-	PkgTree()
-	PkgDiff()
-	FnRender()
-	FnEval(string, string, string, string)
-	LiveInit()
-	LiveApply()
-	LiveStatus()
-	SetLocalPath(string)
+	PkgGet(context.Context, *CommandOptions, *Package) error
+	PkgTree(context.Context, *CommandOptions) error
+	PkgDiff(context.Context, *CommandOptions) error
+	FnRender(context.Context, *CommandOptions) error
+	FnEval(context.Context, *CommandOptions, string, string, string, string) error
+	LiveInit(context.Context, *CommandOptions) error
+	LiveApply(context.Context, *CommandOptions) error
+	LiveDestroy(context.Context, *CommandOptions) error
 }
 
+// applyGracePeriod is the time given to kpt to report its own reconcile timeout before the
+// apply attempts are cancelled.
+const applyGracePeriod = time.Minute
+
+// CommandLine runs the kpt binary. It doesn't hold any state, so it's safe for concurrent use.
 type CommandLine struct {
-	localPath string
+	// FileSystem holds the local packages, the disk is used when it isn't provided
+	FileSystem filesys.FileSystem
 }
 
 var _ Client = (*CommandLine)(nil)
 
-func (c *CommandLine) SetLocalPath(localPath string) {
-	c.localPath = localPath
+func (c *CommandLine) fSys() filesys.FileSystem {
+	if c.FileSystem == nil {
+		return filesys.MakeFsOnDisk()
+	}
+
+	return c.FileSystem
+}
+
+func (c *CommandLine) runCmd(ctx context.Context, opts *CommandOptions, args ...string) error {
+	var out bytes.Buffer
+
+	err := c.streamCmd(ctx, opts, func(stdout io.Reader) error {
+		_, err := io.Copy(&out, stdout)
+
+		return errors.Wrap(err, "failed to read the kpt output")
+	}, args...)
+
+	opts.logger().V(logging.LevelTrace).Info("kpt command output", "command", commandName(args),
+		"output", out.String())
+
+	return err
+}
+
+func commandName(args []string) string {
+	return "kpt " + strings.Join(args[:2], " ")
+}
+
+// operationType returns the operation type of the kpt command, the debug commands don't have one.
+func operationType(args []string) string {
+	switch commandName(args) {
+	case "kpt pkg get":
+		return OperationFetch
+	case "kpt fn render", "kpt fn eval":
+		return OperationFn
+	case "kpt live init", "kpt live apply", "kpt live destroy":
+		return OperationApply
+	}
+
+	return ""
 }
 
-func (c CommandLine) runCmd(args ...string) {
+// streamCmd runs the kpt command provided, passing its standard output to the handler
+// while the command is running. The transient failures are retried according to the
+// policy of the command operation type.
+func (c *CommandLine) streamCmd(ctx context.Context, opts *CommandOptions, handler func(io.Reader) error,
+	args ...string,
+) error {
+	operation := operationType(args)
+
+	policy := RetryPolicy{MaxAttempts: 1}
+	if len(operation) != 0 {
+		policy = opts.Retry.Policy(operation)
+	}
+
+	attempts := 0
+
+	err := policy.Run(ctx, opts.logger().WithValues("command", commandName(args)), func() error {
+		attempts++
+		if attempts > 1 && operation == OperationFetch && c.fSys().Exists(opts.Path) {
+			// The package is only fetched when its directory doesn't exist, so the partial
+			// package of the failed attempt has to be removed before retrying
+			if err := c.fSys().RemoveAll(opts.Path); err != nil {
+				return errors.Wrapf(err, "failed to remove the partial %s package", opts.Path)
+			}
+		}
+
+		start := time.Now()
+		exitCode, err := c.execCmd(ctx, opts, handler, args...)
+
+		if opts.Observer != nil {
+			opts.Observer(CommandResult{Args: args, Duration: time.Since(start), ExitCode: exitCode, Err: err})
+		}
+
+		return err
+	})
+	if err != nil {
+		opts.logger().Error(err, "kpt command failed", "command", commandName(args))
+	}
+
+	return err
+}
+
+func (c *CommandLine) execCmd(ctx context.Context, opts *CommandOptions, handler func(io.Reader) error,
+	args ...string,
+) (int, error) {
 	kptExecPath, err := exec.LookPath("kpt")
 	if err != nil {
-		log.Println(err)
+		return -1, errors.Wrap(err, "failed to find the kpt binary")
 	}
 
-	command := &exec.Cmd{
-		Path:   kptExecPath,
-		Args:   append([]string{kptExecPath}, args...),
-		Stdout: os.Stdout,
-		Stderr: os.Stderr,
+	var stderr bytes.Buffer
+
+	opts.logger().V(logging.LevelDebug).Info("Running kpt command", "command", commandName(args), "args", args)
+
+	command := exec.CommandContext(ctx, kptExecPath, args...)
+	command.Stderr = &stderr
+
+	stdout, err := command.StdoutPipe()
+	if err != nil {
+		return -1, errors.Wrap(err, "failed to get the kpt output")
 	}
 
-	// set var to get the output
-	var out bytes.Buffer
+	if err := command.Start(); err != nil {
+		return -1, errors.Wrapf(err, "failed to start the %s command", commandName(args))
+	}
+
+	handlerErr := handler(stdout)
+	// Drain any remaining output, so the command doesn't block on a full pipe
+	_, _ = io.Copy(io.Discard, stdout)
+
+	err = command.Wait()
+	exitCode := command.ProcessState.ExitCode()
 
-	// set the output to our variable
-	command.Stdout = &out
-	if err := command.Run(); err != nil {
-		log.Println(err)
+	if err != nil && handlerErr == nil {
+		return exitCode, errors.Wrapf(err, "%s command failed: %s", commandName(args),
+			strings.TrimSpace(stderr.String()))
 	}
+
+	return exitCode, handlerErr
 }
 
-func (c *CommandLine) PkgGet(pkg *Package) {
-	_, err := os.Stat(c.localPath)
+// PkgGet fetches the package provided, unless it was already fetched into the local path.
+func (c *CommandLine) PkgGet(ctx context.Context, opts *CommandOptions, pkg *Package) error {
+	if c.fSys().Exists(opts.Path) {
+		opts.logger().V(logging.LevelDebug).Info("Package already fetched", "path", opts.Path)
 
-	if os.IsExist(err) {
-		return
+		return nil
 	}
 
-	args := []string{"pkg", "get", pkg.String(), c.localPath, "--for-deployment"}
-	c.runCmd(args...)
+	args := []string{"pkg", "get", pkg.String(), opts.Path, "--for-deployment"}
+
+	return c.runCmd(ctx, opts, args...)
 }
 
-func (c *CommandLine) PkgTree() {
-	args := []string{"pkg", "tree", c.localPath}
-	c.runCmd(args...)
+func (c *CommandLine) PkgTree(ctx context.Context, opts *CommandOptions) error {
+	args := []string{"pkg", "tree", opts.Path}
+
+	return c.runCmd(ctx, opts, args...)
 }
 
-func (c *CommandLine) PkgDiff() {
-	args := []string{"pkg", "diff", c.localPath}
-	c.runCmd(args...)
+func (c *CommandLine) PkgDiff(ctx context.Context, opts *CommandOptions) error {
+	args := []string{"pkg", "diff", opts.Path}
+
+	return c.runCmd(ctx, opts, args...)
 }
 
-func (c *CommandLine) FnRender() {
-	args := []string{"fn", "render", c.localPath}
-	c.runCmd(args...)
+func (c *CommandLine) FnRender(ctx context.Context, opts *CommandOptions) error {
+	args := []string{"fn", "render", opts.Path}
+
+	return c.runCmd(ctx, opts, args...)
 }
 
-func (c *CommandLine) FnEval(image, byPath, byValueRegex, putValue string) {
+func (c *CommandLine) FnEval(ctx context.Context, opts *CommandOptions,
+	image, byPath, byValueRegex, putValue string,
+) error {
 	args := []string{
-		"fn", "eval", c.localPath, "--save",
+		"fn", "eval", opts.Path, "--save",
 		"--type", "mutator", "--image", image, "--",
 		"by-path=" + byPath, "by-value-regex=" + byValueRegex,
 		"put-value=" + putValue,
 	}
-	c.runCmd(args...)
+
+	return c.runCmd(ctx, opts, args...)
 }
 
-func (c *CommandLine) LiveInit() {
-	args := []string{"live", "init", c.localPath, "--force"}
-	c.runCmd(args...)
+func (c *CommandLine) LiveInit(ctx context.Context, opts *CommandOptions) error {
+	args := append([]string{"live", "init", opts.Path, "--force"}, opts.clusterArgs()...)
+
+	return c.runCmd(ctx, opts, args...)
 }
 
-func (c *CommandLine) LiveApply() {
-	args := []string{"live", "apply", c.localPath, "--reconcile-timeout", "15m"}
-	c.runCmd(args...)
+// LiveApply applies the package resources, reporting the number of reconciled
+// resources while kpt waits for them. The retries share the reconcile timeout.
+func (c *CommandLine) LiveApply(ctx context.Context, opts *CommandOptions) error {
+	args := []string{"live", "apply", opts.Path, "--output", "json", "--show-status-events"}
+	if opts.ReconcileTimeout != 0 {
+		args = append(args, "--reconcile-timeout", opts.ReconcileTimeout.String())
+
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, opts.ReconcileTimeout+applyGracePeriod)
+		defer cancel()
+	}
+
+	args = append(args, opts.clusterArgs()...)
+
+	return c.streamCmd(ctx, opts, func(stdout io.Reader) error {
+		progress, err := ParseApplyEvents(stdout, opts.logger(), func(progress *ApplyProgress) {
+			opts.logger().Info(progress.String(), "path", opts.Path)
+		})
+		if err != nil {
+			return err
+		}
+
+		return errors.Wrapf(progress.Err(), "failed to apply the %s package", opts.Path)
+	}, args...)
 }
 
-func (c *CommandLine) LiveStatus() {
-	args := []string{"live", "status", c.localPath}
-	c.runCmd(args...)
+// LiveDestroy deletes the package resources recorded in its inventory from the cluster.
+func (c *CommandLine) LiveDestroy(ctx context.Context, opts *CommandOptions) error {
+	args := append([]string{"live", "destroy", opts.Path}, opts.clusterArgs()...)
+
+	return c.runCmd(ctx, opts, args...)
 }
@@ -0,0 +1,196 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kpt
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/electrocucaracha/nephioadm/internal/logging"
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+)
+
+// Event types emitted by kpt live apply when the JSON output is used.
+const (
+	EventTypeGroup   = "group"
+	EventTypeApply   = "apply"
+	EventTypePrune   = "prune"
+	EventTypeWait    = "wait"
+	EventTypeStatus  = "status"
+	EventTypeSummary = "summary"
+	EventTypeError   = "error"
+)
+
+// Event statuses reported by the apply, prune and wait events.
+const (
+	EventStatusSuccessful = "Successful"
+	EventStatusFailed     = "Failed"
+	EventStatusTimeout    = "Timeout"
+)
+
+// Event is an entry of the kpt live apply JSON event stream.
+type Event struct {
+	Type      string `json:"type"`
+	Timestamp string `json:"timestamp,omitempty"`
+
+	// Resource events (apply, prune, wait and status)
+	Group     string `json:"group,omitempty"`
+	Kind      string `json:"kind,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Status    string `json:"status,omitempty"`
+	Message   string `json:"message,omitempty"`
+	Error     string `json:"error,omitempty"`
+
+	// Group and summary events
+	Action     string `json:"action,omitempty"`
+	Count      int    `json:"count,omitempty"`
+	Successful int    `json:"successful,omitempty"`
+	Skipped    int    `json:"skipped,omitempty"`
+	Failed     int    `json:"failed,omitempty"`
+	Timeout    int    `json:"timeout,omitempty"`
+}
+
+// Resource returns the identifier of the resource referenced by the event.
+func (e Event) Resource() string {
+	kind := e.Kind
+	if len(e.Group) != 0 {
+		kind += "." + e.Group
+	}
+
+	if len(e.Namespace) != 0 {
+		return kind + " " + e.Namespace + "/" + e.Name
+	}
+
+	return kind + " " + e.Name
+}
+
+// ApplyProgress aggregates the events of an apply operation.
+type ApplyProgress struct {
+	applied    map[string]bool
+	reconciled map[string]bool
+	messages   map[string]string
+	failures   map[string]string
+	errors     []string
+}
+
+func NewApplyProgress() *ApplyProgress {
+	return &ApplyProgress{
+		applied:    map[string]bool{},
+		reconciled: map[string]bool{},
+		messages:   map[string]string{},
+		failures:   map[string]string{},
+		errors:     []string{},
+	}
+}
+
+// Handle updates the progress with the event provided and reports whether the
+// number of reconciled resources has changed.
+func (p *ApplyProgress) Handle(event *Event) bool {
+	resource := event.Resource()
+
+	switch event.Type {
+	case EventTypeApply:
+		p.applied[resource] = true
+
+		if event.Status == EventStatusFailed {
+			p.failures[resource] = event.Error
+		}
+	case EventTypePrune:
+		if event.Status == EventStatusFailed {
+			p.failures[resource] = event.Error
+		}
+	case EventTypeStatus:
+		if len(event.Message) != 0 {
+			p.messages[resource] = event.Status + ": " + event.Message
+		}
+	case EventTypeWait:
+		switch event.Status {
+		case EventStatusSuccessful:
+			changed := !p.reconciled[resource]
+			p.reconciled[resource] = true
+
+			return changed
+		case EventStatusFailed, EventStatusTimeout:
+			p.failures[resource] = "reconcile " + strings.ToLower(event.Status)
+		}
+	case EventTypeError:
+		p.errors = append(p.errors, event.Error)
+	}
+
+	return false
+}
+
+func (p *ApplyProgress) String() string {
+	return fmt.Sprintf("%d/%d resources reconciled", len(p.reconciled), len(p.applied))
+}
+
+// Err returns an error naming the resources which failed and their last status message.
+func (p *ApplyProgress) Err() error {
+	if len(p.failures) == 0 && len(p.errors) == 0 {
+		return nil
+	}
+
+	failures := make([]string, 0, len(p.failures))
+
+	for resource, reason := range p.failures {
+		if message, ok := p.messages[resource]; ok {
+			reason += " (" + message + ")"
+		}
+
+		failures = append(failures, resource+": "+reason)
+	}
+
+	sort.Strings(failures)
+
+	return errors.Errorf("%d resource(s) failed to be applied: %s", len(failures)+len(p.errors),
+		strings.Join(append(failures, p.errors...), "; "))
+}
+
+// ParseApplyEvents reads the kpt live apply JSON event stream, notifying every change of the
+// reconciled resources through the function provided. The lines which aren't JSON events, like
+// the kpt warnings, are only logged.
+func ParseApplyEvents(reader io.Reader, logger logr.Logger, notify func(*ApplyProgress)) (*ApplyProgress, error) {
+	progress := NewApplyProgress()
+	scanner := bufio.NewScanner(reader)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 {
+			continue
+		}
+
+		var event Event
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			logger.V(logging.LevelTrace).Info("Skipping the apply output", "line", line, "reason", err.Error())
+
+			continue
+		}
+
+		if progress.Handle(&event) && notify != nil {
+			notify(progress)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return progress, errors.Wrap(err, "failed to read the apply events")
+	}
+
+	return progress, nil
+}
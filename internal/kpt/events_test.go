@@ -0,0 +1,84 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+	http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kpt_test
+
+import (
+	"strings"
+
+	"github.com/electrocucaracha/nephioadm/internal/kpt"
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const (
+	applyEvent = `{"type":"apply","group":"apps","kind":"Deployment","namespace":"nephio-system",` +
+		`"name":"package-deployment-controller","status":"Successful"}`
+	serviceApplyEvent = `{"type":"apply","kind":"Service","namespace":"nephio-system",` +
+		`"name":"package-deployment-controller","status":"Successful"}`
+	serviceWaitEvent = `{"type":"wait","kind":"Service","namespace":"nephio-system",` +
+		`"name":"package-deployment-controller","status":"Successful"}`
+	statusEvent = `{"type":"status","group":"apps","kind":"Deployment","namespace":"nephio-system",` +
+		`"name":"package-deployment-controller","status":"InProgress","message":"Available: 0/1"}`
+	timeoutEvent = `{"type":"wait","group":"apps","kind":"Deployment","namespace":"nephio-system",` +
+		`"name":"package-deployment-controller","status":"Timeout"}`
+)
+
+var _ = Describe("Apply events", func() {
+	It("should report the reconciled resources progress", func() {
+		events := strings.Join([]string{
+			`{"type":"group","action":"Apply","status":"Started"}`,
+			applyEvent, serviceApplyEvent,
+			`{"type":"summary","action":"Apply","count":2,"successful":2}`,
+			serviceWaitEvent, serviceWaitEvent,
+		}, "\n")
+		reports := []string{}
+
+		progress, err := kpt.ParseApplyEvents(strings.NewReader(events), logr.Discard(), func(progress *kpt.ApplyProgress) {
+			reports = append(reports, progress.String())
+		})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reports).To(Equal([]string{"1/2 resources reconciled"}))
+		Expect(progress.Err()).NotTo(HaveOccurred())
+	})
+
+	DescribeTable("failed resources", func(expected string, events ...string) {
+		progress, err := kpt.ParseApplyEvents(strings.NewReader(strings.Join(events, "\n")), logr.Discard(), nil)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(progress.Err()).To(MatchError(ContainSubstring(expected)))
+	},
+		Entry("when a resource isn't reconciled on time",
+			"Deployment.apps nephio-system/package-deployment-controller: reconcile timeout (InProgress: Available: 0/1)",
+			applyEvent, statusEvent, timeoutEvent),
+		Entry("when a resource can't be applied", "Service nephio-system/package-deployment-controller: forbidden",
+			`{"type":"apply","kind":"Service","namespace":"nephio-system","name":"package-deployment-controller",`+
+				`"status":"Failed","error":"forbidden"}`),
+		Entry("when kpt reports an error", "inventory not found", `{"type":"error","error":"inventory not found"}`),
+		Entry("when kpt reports an error and a resource fails", "2 resource(s) failed to be applied",
+			timeoutEvent, `{"type":"error","error":"inventory not found"}`),
+	)
+
+	It("should skip the lines which aren't events", func() {
+		progress, err := kpt.ParseApplyEvents(strings.NewReader(strings.Join([]string{
+			"W1019 12:00:00.000000 warnings.go:70] policy/v1beta1 PodSecurityPolicy is deprecated",
+			applyEvent, timeoutEvent,
+		}, "\n")), logr.Discard(), nil)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(progress.String()).To(Equal("0/1 resources reconciled"))
+		Expect(progress.Err()).To(MatchError(HavePrefix("1 resource(s) failed to be applied")))
+	})
+})
@@ -0,0 +1,105 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+	http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kpt
+
+import (
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+// LockedCommit returns the upstream commit locked in the Kptfile of the package directory, it's
+// empty when the package wasn't fetched from a git repository.
+func LockedCommit(fSys filesys.FileSystem, path string) string {
+	data, err := fSys.ReadFile(filepath.Join(path, "Kptfile"))
+	if err != nil {
+		return ""
+	}
+
+	kptfile, err := yaml.Parse(string(data))
+	if err != nil {
+		return ""
+	}
+
+	// The raw value is read since the commits could be parsed as numbers
+	commit, err := kptfile.Pipe(yaml.Lookup("upstreamLock", "git", "commit"))
+	if err != nil || commit == nil {
+		return ""
+	}
+
+	return commit.YNode().Value
+}
+
+// SetPackageName names the package after its directory, as kpt does when it fetches the package.
+func SetPackageName(fSys filesys.FileSystem, path string) error {
+	file := filepath.Join(path, "Kptfile")
+	if !fSys.Exists(file) {
+		return nil
+	}
+
+	data, err := fSys.ReadFile(file)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read the %s Kptfile", path)
+	}
+
+	kptfile, err := yaml.Parse(string(data))
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse the %s Kptfile", path)
+	}
+
+	if err := kptfile.SetName(filepath.Base(path)); err != nil {
+		return errors.Wrapf(err, "failed to name the %s package", path)
+	}
+
+	content, err := kptfile.String()
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode the %s Kptfile", path)
+	}
+
+	return errors.Wrapf(fSys.WriteFile(file, []byte(content)), "failed to write the %s Kptfile", path)
+}
+
+// WriteInventoryPackage writes a package which only contains a Kptfile with the inventory provided,
+// so the kpt live commands manage the resources recorded in it without the original package.
+func WriteInventoryPackage(fSys filesys.FileSystem, path, namespace, name, inventoryID string) error {
+	kptfile, err := yaml.Parse("apiVersion: kpt.dev/v1\nkind: Kptfile\n")
+	if err != nil {
+		return errors.Wrap(err, "failed to create the Kptfile")
+	}
+
+	if err := kptfile.SetName(filepath.Base(path)); err != nil {
+		return errors.Wrapf(err, "failed to name the %s package", path)
+	}
+
+	for _, field := range [][2]string{{"namespace", namespace}, {"name", name}, {"inventoryID", inventoryID}} {
+		if err := kptfile.PipeE(yaml.LookupCreate(yaml.MappingNode, "inventory"),
+			yaml.SetField(field[0], yaml.NewStringRNode(field[1]))); err != nil {
+			return errors.Wrapf(err, "failed to set the %s package inventory", path)
+		}
+	}
+
+	content, err := kptfile.String()
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode the %s Kptfile", path)
+	}
+
+	if err := fSys.MkdirAll(path); err != nil {
+		return errors.Wrapf(err, "failed to create the %s package", path)
+	}
+
+	return errors.Wrapf(fSys.WriteFile(filepath.Join(path, "Kptfile"), []byte(content)),
+		"failed to write the %s Kptfile", path)
+}
@@ -0,0 +1,151 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kpt
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+)
+
+// Operation types, each of them has its own retry policy.
+const (
+	// OperationFetch fetches the remote packages (kpt pkg get)
+	OperationFetch = "fetch"
+	// OperationFn runs the kpt functions (kpt fn render and kpt fn eval)
+	OperationFn = "fn"
+	// OperationApply changes the cluster resources (kpt live init, apply and destroy)
+	OperationApply = "apply"
+)
+
+// Operations lists the operation types with a retry policy.
+var Operations = []string{OperationFetch, OperationFn, OperationApply}
+
+// retryableErrors are the messages of the transient network, Git and Kubernetes API failures.
+var retryableErrors = []string{
+	"connection refused",
+	"connection reset by peer",
+	"broken pipe",
+	"i/o timeout",
+	"tls handshake timeout",
+	"no such host",
+	"temporary failure in name resolution",
+	"could not resolve host",
+	"unexpected eof",
+	"early eof",
+	"the remote end hung up unexpectedly",
+	"server sent goaway",
+	"too many requests",
+	"rate limit",
+	"internal error occurred",
+	"the server is currently unable to handle the request",
+	"etcdserver: request timed out",
+	"502 bad gateway",
+	"503 service unavailable",
+	"504 gateway timeout",
+}
+
+// IsRetryable reports whether the kpt failure provided is transient, so the command may succeed
+// when it's run again. The cancellation of the context is never retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	message := strings.ToLower(err.Error())
+
+	for _, retryable := range retryableErrors {
+		if strings.Contains(message, retryable) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RetryPolicy defines the attempts of an operation and the exponential backoff between them.
+type RetryPolicy struct {
+	MaxAttempts int
+	// Backoff is the delay before the first retry, it's doubled on every retry up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// RetryPolicies are the retry policies indexed by operation type.
+type RetryPolicies map[string]RetryPolicy
+
+// DefaultRetryPolicies returns the policies used when the operation type doesn't have one.
+func DefaultRetryPolicies() RetryPolicies {
+	return RetryPolicies{
+		OperationFetch: {MaxAttempts: 4, Backoff: 2 * time.Second, MaxBackoff: 30 * time.Second},
+		OperationFn:    {MaxAttempts: 2, Backoff: time.Second, MaxBackoff: 10 * time.Second},
+		OperationApply: {MaxAttempts: 3, Backoff: 5 * time.Second, MaxBackoff: time.Minute},
+	}
+}
+
+// Policy returns the retry policy of the operation type provided.
+func (p RetryPolicies) Policy(operation string) RetryPolicy {
+	if policy, ok := p[operation]; ok {
+		return policy
+	}
+
+	return DefaultRetryPolicies()[operation]
+}
+
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	backoff := p.Backoff
+
+	for i := 1; i < retry; i++ {
+		backoff *= 2
+		if p.MaxBackoff != 0 && backoff >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+
+	return backoff
+}
+
+// Run runs the attempt function until it succeeds, fails with a fatal error, the maximum
+// number of attempts is reached or the context deadline doesn't leave time for another one.
+// Every retry is logged with its backoff and the previous error.
+func (p RetryPolicy) Run(ctx context.Context, logger logr.Logger, attempt func() error) error {
+	maxAttempts := p.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	for i := 1; ; i++ {
+		err := attempt()
+		if err == nil || i >= maxAttempts || !IsRetryable(err) {
+			return err
+		}
+
+		backoff := p.backoff(i)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < backoff {
+			return errors.Wrapf(err, "no time left to retry after %d attempt(s)", i)
+		}
+
+		logger.Info("Retrying after a transient failure", "attempt", i+1, "maxAttempts", maxAttempts,
+			"backoff", backoff.String(), "error", err.Error())
+
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "retry aborted after %v", err)
+		case <-time.After(backoff):
+		}
+	}
+}
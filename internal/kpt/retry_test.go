@@ -0,0 +1,113 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kpt_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/electrocucaracha/nephioadm/internal/kpt"
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+var _ = Describe("Retry policy", func() {
+	DescribeTable("failure classification", func(message string, retryable bool) {
+		Expect(kpt.IsRetryable(errors.New(message))).To(Equal(retryable))
+	},
+		Entry("when the Git server drops the connection",
+			"kpt pkg get command failed: fatal: the remote end hung up unexpectedly", true),
+		Entry("when the API server isn't ready", "kpt live apply command failed: dial tcp 127.0.0.1:6443: "+
+			"connect: connection refused", true),
+		Entry("when GitHub rate limits the requests", "kpt pkg get command failed: 429 Too Many Requests", true),
+		Entry("when the package doesn't exist", "kpt pkg get command failed: repository not found", false),
+		Entry("when a resource is invalid", "kpt live apply command failed: admission webhook denied the request",
+			false),
+	)
+
+	It("should not retry the cancelled commands", func() {
+		Expect(kpt.IsRetryable(errors.Wrap(context.Canceled, "connection refused"))).To(BeFalse())
+	})
+
+	DescribeTable("attempts", func(policy kpt.RetryPolicy, expectedAttempts int, errs ...error) {
+		attempts := 0
+
+		err := policy.Run(context.Background(), logr.Discard(), func() error {
+			attempts++
+			if attempts > len(errs) {
+				return nil
+			}
+
+			return errs[attempts-1]
+		})
+
+		Expect(attempts).To(Equal(expectedAttempts))
+		if expectedAttempts > len(errs) {
+			Expect(err).NotTo(HaveOccurred())
+		} else {
+			Expect(err).To(MatchError(errs[expectedAttempts-1]))
+		}
+	},
+		Entry("when the transient failures are recovered",
+			kpt.RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}, 3,
+			errors.New("i/o timeout"), errors.New("connection reset by peer")),
+		Entry("when the maximum attempts are reached",
+			kpt.RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond, MaxBackoff: time.Millisecond}, 2,
+			errors.New("i/o timeout"), errors.New("i/o timeout"), errors.New("i/o timeout")),
+		Entry("when the failure is fatal",
+			kpt.RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}, 1,
+			errors.New("repository not found")),
+		Entry("when the policy isn't defined", kpt.RetryPolicy{}, 1, errors.New("i/o timeout")),
+	)
+
+	It("should stop retrying when the context is cancelled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		attempts := 0
+
+		err := kpt.RetryPolicy{MaxAttempts: 3, Backoff: time.Hour}.Run(ctx, logr.Discard(), func() error {
+			attempts++
+			cancel()
+
+			return errors.New("i/o timeout")
+		})
+
+		Expect(attempts).To(Equal(1))
+		Expect(err).To(MatchError(ContainSubstring("retry aborted after i/o timeout")))
+	})
+
+	It("should not retry when the deadline comes before the backoff", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		attempts := 0
+
+		err := kpt.RetryPolicy{MaxAttempts: 3, Backoff: time.Hour}.Run(ctx, logr.Discard(), func() error {
+			attempts++
+
+			return errors.New("i/o timeout")
+		})
+
+		Expect(attempts).To(Equal(1))
+		Expect(err).To(MatchError(ContainSubstring("no time left to retry after 1 attempt(s)")))
+	})
+
+	It("should use the default policy of the operations without one", func() {
+		policies := kpt.RetryPolicies{kpt.OperationFetch: {MaxAttempts: 1}}
+
+		Expect(policies.Policy(kpt.OperationFetch).MaxAttempts).To(Equal(1))
+		Expect(policies.Policy(kpt.OperationApply)).To(Equal(kpt.DefaultRetryPolicies()[kpt.OperationApply]))
+	})
+})
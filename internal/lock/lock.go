@@ -0,0 +1,168 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+	http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lock
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+)
+
+// FileName is the name of the lock file written into the base path by default.
+const FileName = "nephioadm.lock"
+
+const kptfileName = "Kptfile"
+
+// File records the resolved revisions of the installed packages, so they can be installed again
+// with the same content.
+type File struct {
+	Packages []Package `json:"packages"`
+}
+
+// Package is the lock data of a fetched component package.
+type Package struct {
+	Component string `json:"component"`
+	RepoURI   string `json:"repo"`
+	Directory string `json:"directory"`
+	// Ref is the requested git reference, it's empty when the default branch was fetched
+	Ref string `json:"ref,omitempty"`
+	// Commit is the commit locked in the Kptfile upstreamLock, it's empty when the package wasn't
+	// fetched from a git repository
+	Commit string `json:"commit,omitempty"`
+	// Files are the SHA-256 checksums of the package files, but the Kptfile, indexed by their relative path
+	Files map[string]string `json:"files"`
+}
+
+// Get returns the lock data of the component provided.
+func (f *File) Get(component string) (Package, bool) {
+	for _, pkg := range f.Packages {
+		if pkg.Component == component {
+			return pkg, true
+		}
+	}
+
+	return Package{}, false
+}
+
+// Set replaces the lock data of the package component, the packages are kept sorted by component.
+func (f *File) Set(pkg Package) {
+	for i := range f.Packages {
+		if f.Packages[i].Component == pkg.Component {
+			f.Packages[i] = pkg
+
+			return
+		}
+	}
+
+	f.Packages = append(f.Packages, pkg)
+	sort.Slice(f.Packages, func(i, j int) bool {
+		return f.Packages[i].Component < f.Packages[j].Component
+	})
+}
+
+// Read decodes the lock file provided.
+func Read(fSys filesys.FileSystem, file string) (*File, error) {
+	data, err := fSys.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the %s lock file", file)
+	}
+
+	lockFile := &File{}
+	if err := yaml.Unmarshal(data, lockFile); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode the %s lock file", file)
+	}
+
+	return lockFile, nil
+}
+
+// Write encodes the lock data into the file provided.
+func Write(fSys filesys.FileSystem, file string, lockFile *File) error {
+	data, err := yaml.Marshal(lockFile)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode the lock file")
+	}
+
+	if err := fSys.MkdirAll(filepath.Dir(file)); err != nil {
+		return errors.Wrapf(err, "failed to create the %s lock file directory", file)
+	}
+
+	return errors.Wrapf(fSys.WriteFile(file, data), "failed to write the %s lock file", file)
+}
+
+// Hash returns the SHA-256 checksums of the files of the package directory provided, indexed by
+// their relative path. The package Kptfile is skipped, since kpt writes the requested ref into it
+// and its upstream commit is locked on its own.
+func Hash(fSys filesys.FileSystem, dir string) (map[string]string, error) {
+	files := map[string]string{}
+
+	err := fSys.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		relPath, err := filepath.Rel(dir, file)
+		if err != nil || relPath == kptfileName {
+			return err
+		}
+
+		content, err := fSys.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		sum := sha256.Sum256(content)
+		files[filepath.ToSlash(relPath)] = hex.EncodeToString(sum[:])
+
+		return nil
+	})
+
+	return files, errors.Wrapf(err, "failed to hash the %s package", dir)
+}
+
+// Verify checks that the package provided has the same revision and content as the locked one.
+func (p Package) Verify(pkg Package) error {
+	switch {
+	case p.RepoURI != pkg.RepoURI:
+		return errors.Errorf("the %s repository doesn't match the %s locked one", pkg.RepoURI, p.RepoURI)
+	case p.Directory != pkg.Directory:
+		return errors.Errorf("the %s directory doesn't match the %s locked one", pkg.Directory, p.Directory)
+	case p.Commit != pkg.Commit:
+		return errors.Errorf("the %s commit doesn't match the %s locked one", pkg.Commit, p.Commit)
+	}
+
+	for name, sum := range p.Files {
+		actual, ok := pkg.Files[name]
+		if !ok {
+			return errors.Errorf("the %s locked file is missing", name)
+		}
+
+		if actual != sum {
+			return errors.Errorf("the %s file checksum doesn't match the locked one", name)
+		}
+	}
+
+	for name := range pkg.Files {
+		if _, ok := p.Files[name]; !ok {
+			return errors.Errorf("the %s file isn't locked", name)
+		}
+	}
+
+	return nil
+}
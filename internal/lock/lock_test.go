@@ -0,0 +1,101 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+	http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lock_test
+
+import (
+	"github.com/electrocucaracha/nephioadm/internal/lock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const lockFile = "/opt/nephio/nephioadm.lock"
+
+var _ = Describe("Lock", func() {
+	var fSys filesys.FileSystem
+	var locked lock.Package
+
+	BeforeEach(func() {
+		fSys = filesys.MakeFsInMemory()
+		Expect(fSys.WriteFile("/opt/nephio/system/Kptfile", []byte("kind: Kptfile\n"))).To(Succeed())
+		Expect(fSys.WriteFile("/opt/nephio/system/crds/crd.yaml", []byte("kind: CustomResourceDefinition\n"))).
+			To(Succeed())
+
+		files, err := lock.Hash(fSys, "/opt/nephio/system")
+		Expect(err).NotTo(HaveOccurred())
+
+		locked = lock.Package{
+			Component: "system", RepoURI: "https://github.com/nephio-project/nephio-packages.git",
+			Directory: "nephio-system", Ref: "v1.0.1", Commit: "1234567890123456789012345678901234567890",
+			Files: files,
+		}
+	})
+
+	It("should hash the package files but the Kptfile by their relative path", func() {
+		Expect(locked.Files).To(HaveLen(1))
+		Expect(locked.Files).To(HaveKeyWithValue("crds/crd.yaml",
+			"15eccff2269fd6ecb58381f0ff86eee35f28fb7b5a259a7d2fd4455966e6ef4d"))
+	})
+
+	It("should write and read the lock file", func() {
+		lockData := &lock.File{}
+		lockData.Set(locked)
+		lockData.Set(lock.Package{Component: "configsync", Directory: "nephio-configsync"})
+		lockData.Set(locked)
+
+		Expect(lock.Write(fSys, lockFile, lockData)).To(Succeed())
+
+		actual, err := lock.Read(fSys, lockFile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actual.Packages).To(HaveLen(2))
+		Expect(actual.Packages[0].Component).To(Equal("configsync"))
+
+		pkg, ok := actual.Get("system")
+		Expect(ok).To(BeTrue())
+		Expect(pkg).To(Equal(locked))
+
+		_, ok = actual.Get("webui")
+		Expect(ok).To(BeFalse())
+	})
+
+	DescribeTable("package verification", func(edit func(*lock.Package), expected string) {
+		pkg := locked
+		pkg.Files = map[string]string{}
+		for name, sum := range locked.Files {
+			pkg.Files[name] = sum
+		}
+
+		edit(&pkg)
+		err := locked.Verify(pkg)
+
+		if len(expected) == 0 {
+			Expect(err).NotTo(HaveOccurred())
+		} else {
+			Expect(err).To(MatchError(ContainSubstring(expected)))
+		}
+	},
+		Entry("when the package matches the lock", func(*lock.Package) {}, ""),
+		Entry("when only the requested ref differs", func(pkg *lock.Package) { pkg.Ref = "main" }, ""),
+		Entry("when the repository differs", func(pkg *lock.Package) { pkg.RepoURI = "/nephio-packages" },
+			"the /nephio-packages repository doesn't match"),
+		Entry("when the commit differs", func(pkg *lock.Package) { pkg.Commit = "abcdef" },
+			"the abcdef commit doesn't match"),
+		Entry("when a file differs", func(pkg *lock.Package) { pkg.Files["crds/crd.yaml"] = "abcdef" },
+			"the crds/crd.yaml file checksum doesn't match"),
+		Entry("when a file is missing", func(pkg *lock.Package) { delete(pkg.Files, "crds/crd.yaml") },
+			"the crds/crd.yaml locked file is missing"),
+		Entry("when a file isn't locked", func(pkg *lock.Package) { pkg.Files["README.md"] = "abcdef" },
+			"the README.md file isn't locked"),
+	)
+})
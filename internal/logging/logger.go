@@ -0,0 +1,161 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+	http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	"github.com/pkg/errors"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Verbosity levels used by nephioadm loggers.
+const (
+	// LevelInfo reports the progress of the installation
+	LevelInfo = 0
+	// LevelDebug reports the kpt commands and the Kubernetes operations
+	LevelDebug = 1
+	// LevelTrace reports the output of the kpt commands
+	LevelTrace = 2
+)
+
+// Formats lists the supported log formats.
+var Formats = []string{FormatText, FormatJSON}
+
+// sink prints the log entries formatted by funcr on its own writer, so every logger derived
+// from it can copy its entries into other writers without changing the global output.
+type sink struct {
+	funcr.Formatter
+	out io.Writer
+}
+
+var _ logr.LogSink = &sink{}
+
+func (s sink) WithName(name string) logr.LogSink {
+	s.Formatter.AddName(name)
+
+	return &s
+}
+
+func (s sink) WithValues(kvList ...interface{}) logr.LogSink {
+	s.Formatter.AddValues(kvList)
+
+	return &s
+}
+
+func (s sink) Info(level int, msg string, kvList ...interface{}) {
+	s.write(s.FormatInfo(level, msg, kvList))
+}
+
+func (s sink) Error(err error, msg string, kvList ...interface{}) {
+	s.write(s.FormatError(err, msg, kvList))
+}
+
+func (s sink) write(prefix, args string) {
+	fmt.Fprintln(s.out, strings.TrimSpace(prefix+" "+args))
+}
+
+// lockedWriter serializes the writes of the loggers running concurrently.
+type lockedWriter struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func (w *lockedWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.out.Write(data)
+}
+
+// NewLogger creates a leveled logger printing entries in the format provided on the writer.
+func NewLogger(out io.Writer, format string, verbosity int) (logr.Logger, error) {
+	opts := funcr.Options{LogTimestamp: true, Verbosity: verbosity}
+
+	switch format {
+	case FormatText:
+		return logr.New(&sink{Formatter: funcr.NewFormatter(opts), out: out}), nil
+	case FormatJSON:
+		return logr.New(&sink{Formatter: funcr.NewFormatterJSON(opts), out: out}), nil
+	}
+
+	return logr.Discard(), errors.Errorf("unsupported %q log format, valid formats are %s",
+		format, strings.Join(Formats, ", "))
+}
+
+// Tee returns a logger which also prints the entries of the logger provided on the writer. The
+// loggers which weren't created by NewLogger are returned as they are.
+func Tee(logger logr.Logger, out io.Writer) logr.Logger {
+	current, ok := logger.GetSink().(*sink)
+	if !ok {
+		return logger
+	}
+
+	tee := *current
+	tee.out = &lockedWriter{out: io.MultiWriter(current.out, out)}
+
+	return logger.WithSink(&tee)
+}
+
+// lineWriter logs every complete line written into it as an entry of the logger.
+type lineWriter struct {
+	logger logr.Logger
+	buffer bytes.Buffer
+}
+
+func (w *lineWriter) Write(data []byte) (int, error) {
+	w.buffer.Write(data)
+
+	for {
+		index := bytes.IndexByte(w.buffer.Bytes(), '\n')
+		if index < 0 {
+			return len(data), nil
+		}
+
+		w.logger.Info(strings.TrimRight(string(w.buffer.Next(index+1)), "\n"))
+	}
+}
+
+// Writer returns a writer which logs every line written into it through the logger provided,
+// so the summaries printed as tables are kept in the same output and format as the entries.
+func Writer(logger logr.Logger) io.Writer {
+	return &lineWriter{logger: OrDefault(logger)}
+}
+
+// Default returns the text logger used when none is configured.
+func Default() logr.Logger {
+	logger, _ := NewLogger(os.Stderr, FormatText, LevelInfo)
+
+	return logger
+}
+
+// OrDefault returns the logger provided or the default one when it isn't initialized.
+func OrDefault(logger logr.Logger) logr.Logger {
+	if logger.GetSink() == nil {
+		return Default()
+	}
+
+	return logger
+}
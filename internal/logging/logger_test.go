@@ -0,0 +1,96 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+	http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/electrocucaracha/nephioadm/internal/logging"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Logger", func() {
+	var out *bytes.Buffer
+
+	BeforeEach(func() {
+		out = new(bytes.Buffer)
+	})
+
+	It("should print JSON entries with their fields", func() {
+		logger, err := logging.NewLogger(out, logging.FormatJSON, logging.LevelInfo)
+		Expect(err).NotTo(HaveOccurred())
+
+		logger.WithValues("phase", "init", "package", "system").Info("Package applied")
+
+		entry := map[string]interface{}{}
+		Expect(json.Unmarshal(out.Bytes(), &entry)).To(Succeed())
+		Expect(entry).To(HaveKeyWithValue("msg", "Package applied"))
+		Expect(entry).To(HaveKeyWithValue("phase", "init"))
+		Expect(entry).To(HaveKeyWithValue("package", "system"))
+	})
+
+	DescribeTable("verbosity levels", func(verbosity, expectedEntries int) {
+		logger, err := logging.NewLogger(out, logging.FormatText, verbosity)
+		Expect(err).NotTo(HaveOccurred())
+
+		logger.Info("info")
+		logger.V(logging.LevelDebug).Info("debug")
+		logger.V(logging.LevelTrace).Info("trace")
+
+		Expect(strings.Count(out.String(), "\n")).To(Equal(expectedEntries))
+	},
+		Entry("when the default verbosity is used", logging.LevelInfo, 1),
+		Entry("when the debug verbosity is used", logging.LevelDebug, 2),
+		Entry("when the trace verbosity is used", logging.LevelTrace, 3),
+	)
+
+	It("should copy the entries of the derived loggers into the writer provided", func() {
+		logger, err := logging.NewLogger(out, logging.FormatText, logging.LevelInfo)
+		Expect(err).NotTo(HaveOccurred())
+
+		phase := new(bytes.Buffer)
+		tee := logging.Tee(logger.WithValues("phase", "join"), phase)
+		tee.WithValues("package", "configsync").Info("Installing package")
+		logger.Info("Not copied")
+
+		Expect(phase.String()).To(ContainSubstring(`"msg"="Installing package" "phase"="join" "package"="configsync"`))
+		Expect(phase.String()).NotTo(ContainSubstring("Not copied"))
+		Expect(strings.Count(out.String(), "\n")).To(Equal(2))
+	})
+
+	It("should log every line written into its writer", func() {
+		logger, err := logging.NewLogger(out, logging.FormatJSON, logging.LevelInfo)
+		Expect(err).NotTo(HaveOccurred())
+
+		writer := logging.Writer(logger.WithValues("package", "system"))
+		_, err = writer.Write([]byte("RESOURCE   STATUS\nDeployment "))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = writer.Write([]byte("  InProgress\n"))
+		Expect(err).NotTo(HaveOccurred())
+
+		entries := strings.Split(strings.TrimSpace(out.String()), "\n")
+		Expect(entries).To(HaveLen(2))
+		Expect(entries[0]).To(ContainSubstring(`"msg":"RESOURCE   STATUS","package":"system"`))
+		Expect(entries[1]).To(ContainSubstring(`"msg":"Deployment   InProgress","package":"system"`))
+	})
+
+	It("should fail when the format is not supported", func() {
+		_, err := logging.NewLogger(out, "xml", logging.LevelInfo)
+
+		Expect(err).To(HaveOccurred())
+	})
+})
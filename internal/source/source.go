@@ -0,0 +1,284 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+	http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/electrocucaracha/nephioadm/internal/k8s"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/yaml"
+)
+
+const (
+	fileScheme   = "file://"
+	kptfileName  = "Kptfile"
+	emptyKptfile = `apiVersion: kpt.dev/v1
+kind: Kptfile
+metadata:
+  annotations:
+    config.kubernetes.io/local-config: "true"
+`
+)
+
+// IsLocal reports whether the repository URI is a local directory or tarball, instead of a git
+// repository. The local repositories are file:// URIs, absolute paths or relative paths starting
+// with a dot.
+func IsLocal(repoURI string) bool {
+	return strings.HasPrefix(repoURI, fileScheme) || filepath.IsAbs(repoURI) || strings.HasPrefix(repoURI, ".")
+}
+
+// LocalPath returns the path of the local repository URI.
+func LocalPath(repoURI string) string {
+	return strings.TrimPrefix(repoURI, fileScheme)
+}
+
+// IsArchive reports whether the local repository is a tarball.
+func IsArchive(repoURI string) bool {
+	for _, suffix := range []string{".tar", ".tar.gz", ".tgz"} {
+		if strings.HasSuffix(repoURI, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Upstream is the git revision an archived package was fetched from.
+type Upstream struct {
+	RepoURI   string
+	Directory string
+	// Ref is the requested git reference, it's empty when the default branch was fetched
+	Ref    string
+	Commit string
+}
+
+// Fetch copies the package subdirectory of the local repository into the dst directory and
+// writes its Kptfile, the dst directory is removed when it fails.
+func Fetch(fSys filesys.FileSystem, repoURI, pkgPath, dst string) error {
+	if IsArchive(repoURI) {
+		return FetchArchive(fSys, LocalPath(repoURI), pkgPath, dst, nil)
+	}
+
+	return writePackage(fSys, dst, nil, func() error {
+		return copyPackage(fSys, LocalPath(repoURI), pkgPath, dst)
+	})
+}
+
+// FetchArchive extracts the package subdirectory of the tarball into the dst directory and writes
+// its Kptfile, the dst directory is removed when it fails. The upstream is recorded in the Kptfile
+// when the archived package was fetched from a git repository, it's nil otherwise.
+func FetchArchive(fSys filesys.FileSystem, file, pkgPath, dst string, upstream *Upstream) error {
+	return writePackage(fSys, dst, upstream, func() error {
+		return extract(fSys, file, pkgPath, dst)
+	})
+}
+
+func writePackage(fSys filesys.FileSystem, dst string, upstream *Upstream, write func() error) error {
+	err := write()
+	if err == nil {
+		err = writeKptfile(fSys, dst, upstream)
+	}
+
+	if err != nil {
+		fSys.RemoveAll(dst)
+
+		return err
+	}
+
+	return nil
+}
+
+func copyPackage(fSys filesys.FileSystem, dir, pkgPath, dst string) error {
+	src := filepath.Join(dir, pkgPath)
+	if !fSys.IsDir(src) {
+		return errors.Errorf("the %s package wasn't found in the %s directory", pkgPath, dir)
+	}
+
+	return errors.Wrapf(k8s.CopyDir(fSys, src, dst), "failed to copy the %s package", pkgPath)
+}
+
+// extract writes the files of the package subdirectory of the tarball into the dst directory.
+// The subdirectory can be nested in a top-level directory, as in the git hosting archives.
+func extract(fSys filesys.FileSystem, file, pkgPath, dst string) error {
+	data, err := fSys.ReadFile(file)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read the %s tarball", file)
+	}
+
+	var reader io.Reader = bytes.NewReader(data)
+	if !strings.HasSuffix(file, ".tar") {
+		if reader, err = gzip.NewReader(reader); err != nil {
+			return errors.Wrapf(err, "failed to decompress the %s tarball", file)
+		}
+	}
+
+	found := false
+	tarReader := tar.NewReader(reader)
+
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return errors.Wrapf(err, "failed to read the %s tarball", file)
+		}
+
+		relPath, ok := packageEntry(header.Name, pkgPath)
+		if !ok {
+			continue
+		}
+
+		found = true
+		target := filepath.Join(dst, relPath)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = fSys.MkdirAll(target)
+		case tar.TypeReg:
+			err = writeEntry(fSys, tarReader, target)
+		}
+
+		if err != nil {
+			return errors.Wrapf(err, "failed to extract the %s file", header.Name)
+		}
+	}
+
+	if !found {
+		return errors.Errorf("the %s package wasn't found in the %s tarball", pkgPath, file)
+	}
+
+	return nil
+}
+
+func writeEntry(fSys filesys.FileSystem, reader io.Reader, target string) error {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	if err := fSys.MkdirAll(filepath.Dir(target)); err != nil {
+		return err
+	}
+
+	return fSys.WriteFile(target, content)
+}
+
+// packageEntry returns the path of the tarball entry relative to the package subdirectory, when
+// the package is at the root or under a top-level directory of the tarball.
+func packageEntry(name, pkgPath string) (string, bool) {
+	name = path.Clean(strings.TrimPrefix(name, "./"))
+	if strings.HasPrefix(name, "../") {
+		return "", false
+	}
+
+	parts := strings.Split(name, "/")
+	pkgParts := strings.Split(path.Clean(pkgPath), "/")
+
+	for offset := 0; offset <= 1 && offset+len(pkgParts) <= len(parts); offset++ {
+		if strings.Join(parts[offset:offset+len(pkgParts)], "/") == path.Clean(pkgPath) {
+			return path.Join(parts[offset+len(pkgParts):]...), true
+		}
+	}
+
+	return "", false
+}
+
+// writeKptfile names the package after its directory, as kpt pkg get does, and replaces the
+// upstream git references with the upstream provided, they're dropped when it's nil since the
+// package isn't fetched from a git repository.
+func writeKptfile(fSys filesys.FileSystem, dst string, upstream *Upstream) error {
+	file := filepath.Join(dst, kptfileName)
+
+	content := []byte(emptyKptfile)
+	if fSys.Exists(file) {
+		data, err := fSys.ReadFile(file)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read the %s Kptfile", dst)
+		}
+
+		content = data
+	}
+
+	kptfile, err := yaml.Parse(string(content))
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse the %s Kptfile", dst)
+	}
+
+	if err := kptfile.SetName(filepath.Base(dst)); err != nil {
+		return errors.Wrapf(err, "failed to name the %s package", dst)
+	}
+
+	for _, field := range []string{"upstream", "upstreamLock"} {
+		if _, err := kptfile.Pipe(yaml.Clear(field)); err != nil {
+			return errors.Wrapf(err, "failed to clear the %s Kptfile %s", dst, field)
+		}
+	}
+
+	if upstream != nil {
+		if err := setUpstream(kptfile, upstream); err != nil {
+			return errors.Wrapf(err, "failed to set the %s Kptfile upstream", dst)
+		}
+	}
+
+	data, err := kptfile.String()
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode the %s Kptfile", dst)
+	}
+
+	return errors.Wrapf(fSys.WriteFile(file, []byte(data)), "failed to write the %s Kptfile", dst)
+}
+
+// setUpstream records the git upstream into the Kptfile, as kpt pkg get does.
+func setUpstream(kptfile *yaml.RNode, upstream *Upstream) error {
+	directory := "/" + strings.TrimPrefix(upstream.Directory, "/")
+
+	fields := []struct {
+		path  []string
+		value string
+	}{
+		{[]string{"upstream", "type"}, "git"},
+		{[]string{"upstream", "git", "repo"}, upstream.RepoURI},
+		{[]string{"upstream", "git", "directory"}, directory},
+		{[]string{"upstream", "git", "ref"}, upstream.Ref},
+		{[]string{"upstreamLock", "type"}, "git"},
+		{[]string{"upstreamLock", "git", "repo"}, upstream.RepoURI},
+		{[]string{"upstreamLock", "git", "directory"}, directory},
+		{[]string{"upstreamLock", "git", "ref"}, upstream.Ref},
+		{[]string{"upstreamLock", "git", "commit"}, upstream.Commit},
+	}
+
+	for _, field := range fields {
+		if len(field.value) == 0 {
+			continue
+		}
+
+		last := len(field.path) - 1
+		if err := kptfile.PipeE(yaml.LookupCreate(yaml.MappingNode, field.path[:last]...),
+			yaml.SetField(field.path[last], yaml.NewStringRNode(field.value))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
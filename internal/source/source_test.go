@@ -0,0 +1,152 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+	http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+
+	"github.com/electrocucaracha/nephioadm/internal/source"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const (
+	deployment = "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: porch-server\n"
+	gitKptfile = `apiVersion: kpt.dev/v1
+kind: Kptfile
+metadata:
+  name: nephio-system
+upstream:
+  type: git
+  git:
+    repo: https://github.com/nephio-project/nephio-packages
+    directory: /nephio-system
+    ref: main
+upstreamLock:
+  type: git
+  git:
+    commit: 0123456789abcdef0123456789abcdef01234567
+info:
+  description: Nephio system
+`
+	expectedKptfile = `apiVersion: kpt.dev/v1
+kind: Kptfile
+metadata:
+  name: system
+info:
+  description: Nephio system
+`
+)
+
+func newTarball(compress bool, files map[string]string) []byte {
+	var buf bytes.Buffer
+
+	var gzipWriter *gzip.Writer
+	tarWriter := tar.NewWriter(&buf)
+
+	if compress {
+		gzipWriter = gzip.NewWriter(&buf)
+		tarWriter = tar.NewWriter(gzipWriter)
+	}
+
+	for name, content := range files {
+		Expect(tarWriter.WriteHeader(&tar.Header{
+			Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg,
+		})).To(Succeed())
+		_, err := tarWriter.Write([]byte(content))
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	Expect(tarWriter.Close()).To(Succeed())
+
+	if gzipWriter != nil {
+		Expect(gzipWriter.Close()).To(Succeed())
+	}
+
+	return buf.Bytes()
+}
+
+var _ = Describe("Source", func() {
+	var fSys filesys.FileSystem
+
+	BeforeEach(func() {
+		fSys = filesys.MakeFsInMemory()
+	})
+
+	DescribeTable("local repository detection", func(repoURI string, expected bool) {
+		Expect(source.IsLocal(repoURI)).To(Equal(expected))
+	},
+		Entry("when a git repository is provided", "https://github.com/nephio-project/nephio-packages.git", false),
+		Entry("when a relative directory is provided", "./my-checkout", true),
+		Entry("when an absolute directory is provided", "/src/nephio-packages", true),
+		Entry("when a file URI is provided", "file:///tmp/pkgs.tar.gz", true),
+	)
+
+	It("should copy the package of a local directory", func() {
+		Expect(fSys.WriteFile("/src/nephio-packages/nephio-system/Kptfile", []byte(gitKptfile))).To(Succeed())
+		Expect(fSys.WriteFile("/src/nephio-packages/nephio-system/porch/deployment.yaml",
+			[]byte(deployment))).To(Succeed())
+
+		Expect(source.Fetch(fSys, "/src/nephio-packages", "nephio-system", "/opt/nephio/system")).To(Succeed())
+
+		Expect(fSys.ReadFile("/opt/nephio/system/Kptfile")).To(BeEquivalentTo(expectedKptfile))
+		Expect(fSys.ReadFile("/opt/nephio/system/porch/deployment.yaml")).To(BeEquivalentTo(deployment))
+	})
+
+	DescribeTable("tarball extraction", func(file string, compress bool, prefix string) {
+		Expect(fSys.WriteFile(file, newTarball(compress, map[string]string{
+			prefix + "nephio-system/Kptfile":               gitKptfile,
+			prefix + "nephio-system/porch/deployment.yaml": deployment,
+			prefix + "nephio-webui/Kptfile":                gitKptfile,
+		}))).To(Succeed())
+
+		Expect(source.Fetch(fSys, "file://"+file, "nephio-system", "/opt/nephio/system")).To(Succeed())
+
+		Expect(fSys.ReadFile("/opt/nephio/system/Kptfile")).To(BeEquivalentTo(expectedKptfile))
+		Expect(fSys.ReadFile("/opt/nephio/system/porch/deployment.yaml")).To(BeEquivalentTo(deployment))
+		Expect(fSys.Exists("/opt/nephio/system/nephio-webui")).To(BeFalse())
+	},
+		Entry("when a gzip tarball is provided", "/tmp/pkgs.tar.gz", true, ""),
+		Entry("when a tarball with a top-level directory is provided", "/tmp/pkgs.tgz", true, "nephio-packages-main/"),
+		Entry("when an uncompressed tarball is provided", "/tmp/pkgs.tar", false, "./"),
+	)
+
+	It("should write a Kptfile when the package doesn't have one", func() {
+		Expect(fSys.WriteFile("/src/nephio-configsync/rootsync.yaml", []byte("kind: RootSync\n"))).To(Succeed())
+
+		Expect(source.Fetch(fSys, "/src", "nephio-configsync", "/opt/nephio/configsync")).To(Succeed())
+
+		Expect(fSys.ReadFile("/opt/nephio/configsync/Kptfile")).To(BeEquivalentTo(`apiVersion: kpt.dev/v1
+kind: Kptfile
+metadata:
+  annotations:
+    config.kubernetes.io/local-config: "true"
+  name: configsync
+`))
+	})
+
+	It("should fail when the package isn't found", func() {
+		Expect(fSys.WriteFile("/tmp/pkgs.tar.gz", newTarball(true, map[string]string{
+			"nephio-webui/Kptfile": gitKptfile,
+		}))).To(Succeed())
+
+		err := source.Fetch(fSys, "/tmp/pkgs.tar.gz", "nephio-system", "/opt/nephio/system")
+
+		Expect(err).To(MatchError("the nephio-system package wasn't found in the /tmp/pkgs.tar.gz tarball"))
+		Expect(fSys.Exists("/opt/nephio/system")).To(BeFalse())
+	})
+})
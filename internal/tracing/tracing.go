@@ -0,0 +1,151 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+	http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerName  = "github.com/electrocucaracha/nephioadm"
+	serviceName = "nephioadm"
+)
+
+// Span attributes reported by nephioadm.
+const (
+	PhaseKey       = attribute.Key("nephioadm.phase")
+	ClusterKey     = attribute.Key("nephioadm.cluster")
+	PackagePathKey = attribute.Key("nephioadm.package.path")
+	PackageRepoKey = attribute.Key("nephioadm.package.repo")
+	PackageNameKey = attribute.Key("nephioadm.package.name")
+	CommandKey     = attribute.Key("kpt.command")
+	ExitCodeKey    = attribute.Key("kpt.exit_code")
+)
+
+// Options defines where the spans are exported to. Tracing is disabled when
+// none of them is provided.
+type Options struct {
+	// Endpoint of an OTLP/HTTP collector, e.g. localhost:4318 or http://collector:4318
+	Endpoint string
+	// File where the spans are written to as JSON
+	File string
+}
+
+// Tracer returns the nephioadm tracer of the tracer provider, the spans aren't recorded when
+// it isn't provided.
+func Tracer(provider trace.TracerProvider) trace.Tracer {
+	if provider == nil {
+		provider = trace.NewNoopTracerProvider()
+	}
+
+	return provider.Tracer(tracerName)
+}
+
+func newOTLPExporter(ctx context.Context, endpoint string) (sdktrace.SpanExporter, error) {
+	opts := []otlptracehttp.Option{}
+
+	switch {
+	case strings.HasPrefix(endpoint, "http://"):
+		opts = append(opts, otlptracehttp.WithInsecure())
+		endpoint = strings.TrimPrefix(endpoint, "http://")
+	case strings.HasPrefix(endpoint, "https://"):
+		endpoint = strings.TrimPrefix(endpoint, "https://")
+	}
+
+	exporter, err := otlptracehttp.New(ctx, append(opts, otlptracehttp.WithEndpoint(endpoint))...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create the %s OTLP exporter", endpoint)
+	}
+
+	return exporter, nil
+}
+
+// Setup creates a tracer provider exporting the spans to the destinations provided, the global
+// tracer provider isn't changed. The returned function flushes the pending spans and releases
+// the exporters.
+func Setup(ctx context.Context, opts *Options) (trace.TracerProvider, func(context.Context) error, error) {
+	closers := []func() error{}
+	providerOpts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+	}
+
+	// The OTLP exporter is released when the file exporter can't be created
+	var otlpExporter sdktrace.SpanExporter
+
+	if len(opts.Endpoint) != 0 {
+		exporter, err := newOTLPExporter(ctx, opts.Endpoint)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		otlpExporter = exporter
+		providerOpts = append(providerOpts, sdktrace.WithBatcher(exporter))
+	}
+
+	if len(opts.File) != 0 {
+		file, err := os.Create(opts.File)
+		if err != nil {
+			shutdownExporter(ctx, otlpExporter)
+
+			return nil, nil, errors.Wrapf(err, "failed to create the %s traces file", opts.File)
+		}
+
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			shutdownExporter(ctx, otlpExporter)
+
+			return nil, nil, errors.Wrap(err, "failed to create the file exporter")
+		}
+
+		closers = append(closers, file.Close)
+		providerOpts = append(providerOpts, sdktrace.WithSyncer(exporter))
+	}
+
+	if len(providerOpts) == 1 {
+		return trace.NewNoopTracerProvider(), func(context.Context) error { return nil }, nil
+	}
+
+	provider := sdktrace.NewTracerProvider(providerOpts...)
+
+	return provider, func(ctx context.Context) error {
+		if err := provider.Shutdown(ctx); err != nil {
+			return errors.Wrap(err, "failed to flush the traces")
+		}
+
+		for _, closeFile := range closers {
+			if err := closeFile(); err != nil {
+				return errors.Wrap(err, "failed to close the traces file")
+			}
+		}
+
+		return nil
+	}, nil
+}
+
+func shutdownExporter(ctx context.Context, exporter sdktrace.SpanExporter) {
+	if exporter != nil {
+		exporter.Shutdown(ctx)
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+	http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/electrocucaracha/nephioadm/internal/tracing"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var _ = Describe("Tracing", func() {
+	It("should write the spans into the traces file", func() {
+		file := filepath.Join(GinkgoT().TempDir(), "traces.json")
+		ctx := context.Background()
+
+		provider, shutdown, err := tracing.Setup(ctx, &tracing.Options{File: file})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(otel.GetTracerProvider()).NotTo(Equal(provider))
+
+		_, span := tracing.Tracer(provider).Start(ctx, "nephioadm init")
+		span.SetAttributes(tracing.PhaseKey.String("init"))
+		span.End()
+
+		Expect(shutdown(ctx)).To(Succeed())
+		Expect(os.ReadFile(file)).To(And(
+			ContainSubstring(`"Name":"nephioadm init"`),
+			ContainSubstring(`"nephioadm.phase"`),
+		))
+	})
+
+	It("should fail when the traces file can't be created", func() {
+		_, _, err := tracing.Setup(context.Background(), &tracing.Options{
+			Endpoint: "http://localhost:4318",
+			File:     filepath.Join(GinkgoT().TempDir(), "non-existing", "traces.json"),
+		})
+
+		Expect(err).To(MatchError(ContainSubstring("failed to create the")))
+	})
+
+	It("should be disabled when no destination is provided", func() {
+		provider, shutdown, err := tracing.Setup(context.Background(), &tracing.Options{})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(provider).To(Equal(trace.NewNoopTracerProvider()))
+		Expect(shutdown(context.Background())).To(Succeed())
+	})
+})
@@ -0,0 +1,34 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+	http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version
+
+import "runtime/debug"
+
+// Version is the nephioadm release, it's set at build time with
+// -ldflags "-X github.com/electrocucaracha/nephioadm/internal/version.Version=<release>".
+var Version string
+
+// Get returns the nephioadm release, the version of the main module is used when it wasn't set at
+// build time (e.g. go install).
+func Get() string {
+	if len(Version) != 0 {
+		return Version
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok && len(info.Main.Version) != 0 {
+		return info.Main.Version
+	}
+
+	return "(devel)"
+}
@@ -0,0 +1,36 @@
+/*
+Copyright © 2023
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version_test
+
+import (
+	"github.com/electrocucaracha/nephioadm/internal/version"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Version", func() {
+	AfterEach(func() {
+		version.Version = ""
+	})
+
+	It("should report the release set at build time", func() {
+		version.Version = "v1.2.3"
+
+		Expect(version.Get()).To(Equal("v1.2.3"))
+	})
+
+	It("should fall back to the module version", func() {
+		Expect(version.Get()).NotTo(BeEmpty())
+	})
+})
@@ -0,0 +1,296 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package nephioadm bootstraps Nephio clusters. It's the library used by the
+// nephioadm command, so the installation of the control plane and the join of
+// workload clusters can be embedded into other Go programs.
+package nephioadm
+
+import (
+	"context"
+	"time"
+
+	"github.com/electrocucaracha/nephioadm/internal/app"
+	"github.com/electrocucaracha/nephioadm/internal/k8s"
+	"github.com/electrocucaracha/nephioadm/internal/kpt"
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const (
+	PhaseInit = app.PhaseInit
+	PhaseJoin = app.PhaseJoin
+
+	ComponentSystem     = app.ComponentSystem
+	ComponentWebUI      = app.ComponentWebUI
+	ComponentConfigSync = app.ComponentConfigSync
+
+	DefaultBasePath      = app.DefaultBasePath
+	DefaultNephioRepoURI = app.DefaultNephioRepoURI
+	DefaultGitServiceURI = app.DefaultGitServiceURI
+	DefaultParallelism   = app.DefaultParallelism
+
+	OperationFetch = kpt.OperationFetch
+	OperationFn    = kpt.OperationFn
+	OperationApply = kpt.OperationApply
+)
+
+type (
+	// KptClient runs the kpt commands of the package operations, it must be safe for concurrent use.
+	KptClient = kpt.Client
+	// Package is a remote kpt package fetched by the KptClient.
+	Package = kpt.Package
+	// PackageOptions defines the repository, path and version of a Package.
+	PackageOptions = kpt.PackageOptions
+	// CommandOptions are the local path, logger and observer of a single kpt operation.
+	CommandOptions = kpt.CommandOptions
+	// RetryPolicy defines the attempts of a kpt operation type and the exponential backoff between them.
+	RetryPolicy = kpt.RetryPolicy
+	// RetryPolicies are the retry policies indexed by kpt operation type.
+	RetryPolicies = kpt.RetryPolicies
+	// CommandResult reports the execution of a kpt command to the KptClient observer.
+	CommandResult = kpt.CommandResult
+
+	// ClusterClient accesses the resources of a Kubernetes cluster.
+	ClusterClient = k8s.ClusterClient
+	// ClusterOptions selects a cluster from a kubeconfig file, the current context is used by default.
+	ClusterOptions = k8s.ClusterOptions
+	// ImageMirror rewrites the container and kpt function images to be pulled from private registries.
+	ImageMirror = k8s.ImageMirror
+	// ImageRule replaces a prefix of the image references.
+	ImageRule = k8s.ImageRule
+
+	// ComponentStatus reports the installation state of a Nephio component.
+	ComponentStatus = app.ComponentStatus
+	// ObjectStatus reports the kstatus of a resource applied by a component package.
+	ObjectStatus = app.ObjectStatus
+	// InventoryObject identifies a resource applied by a component package.
+	InventoryObject = app.InventoryObject
+	// InstallState records the components and customizations nephioadm installed in a cluster.
+	InstallState = app.InstallState
+	// ComponentState records the package revision of an installed component.
+	ComponentState = app.ComponentState
+)
+
+// ClusterFactory connects to the cluster selected by the options provided.
+type ClusterFactory func(*ClusterOptions) (ClusterClient, error)
+
+// Backends are the implementations used to run kpt, to access the clusters, to store the
+// packages and to report the operations. The kpt command line, the kubeconfig clusters and
+// the local disk are used when they aren't provided, the spans aren't recorded without a
+// TracerProvider.
+type Backends struct {
+	Kpt            KptClient
+	NewCluster     ClusterFactory
+	FileSystem     filesys.FileSystem
+	Logger         logr.Logger
+	TracerProvider trace.TracerProvider
+}
+
+// CommonOptions are the options shared by the Init and Join operations.
+type CommonOptions struct {
+	// Cluster where the components are installed, the current context is used by default
+	Cluster ClusterOptions
+	// BasePath is the local directory where the packages are written to
+	BasePath string
+	// NephioRepoURI is the git repository containing the Nephio packages as subdirectories
+	NephioRepoURI string
+	// NephioRepoRef is the git reference the packages are fetched from, the default branch by default
+	NephioRepoRef string
+	// GitServiceURI is the Git service used by ConfigSync
+	GitServiceURI string
+	// PatchesDir contains the patches of each package in a subdirectory named after its component
+	PatchesDir string
+
+	SkipVerify       bool
+	VerifyTimeout    time.Duration
+	ReconcileTimeout time.Duration
+
+	// Parallelism limits the package operations run concurrently, they're run sequentially when it isn't set
+	Parallelism int
+	// RetryPolicies override the default retry policies of the kpt operation types
+	RetryPolicies RetryPolicies
+	// CacheDir stores the fetched packages, so they're copied instead of fetched again
+	CacheDir string
+	// Bundle is an offline archive the packages are installed from, instead of the Nephio repository
+	Bundle string
+	// ImageMirror rewrites the images of the packages and the kpt functions before rendering them
+	ImageMirror ImageMirror
+	// LockFile records the resolved revisions of the fetched packages, nephioadm.lock of the base path by default
+	LockFile string
+	// Locked refuses to install the packages which differ from the lock file
+	Locked bool
+	// Resume skips the package steps completed by an interrupted run, they're verified instead
+	Resume bool
+
+	// ReportFile and JUnitFile are the files where the results of the phase steps are written to
+	ReportFile string
+	JUnitFile  string
+
+	// WriteLogs copies the logs of the operation into files of the logs directory of the base path
+	WriteLogs bool
+	// Debug runs kpt pkg tree and kpt pkg diff on every package
+	Debug bool
+}
+
+// InitOptions configures the installation of the Nephio control plane.
+type InitOptions struct {
+	CommonOptions
+
+	BackendBaseURL   string
+	WebUIClusterType string
+}
+
+// JoinOptions configures the join of a workload cluster to the Nephio control plane.
+type JoinOptions struct {
+	CommonOptions
+
+	// Management is the cluster where the workload cluster is registered, the
+	// registration is skipped when it isn't provided
+	Management    ClusterOptions
+	ClusterName   string
+	ClusterRegion string
+	ClusterLabels map[string]string
+}
+
+// ResetOptions configures the removal of the Nephio components.
+type ResetOptions struct {
+	// Cluster where the components are removed from, the current context is used by default
+	Cluster  ClusterOptions
+	BasePath string
+	// Phase limits the reset to the components installed on it, all of them are reset when it's empty
+	Phase string
+	// KeepPackages preserves the local packages once their resources are deleted
+	KeepPackages bool
+}
+
+// StatusOptions selects the cluster and the base path whose components are reported.
+type StatusOptions struct {
+	Cluster  ClusterOptions
+	BasePath string
+}
+
+// Client runs the nephioadm operations.
+type Client struct {
+	provider       *app.NephioProvider
+	tracerProvider trace.TracerProvider
+}
+
+// New creates a client which uses the backends provided.
+func New(backends Backends) *Client {
+	fSys := backends.FileSystem
+	if fSys == nil {
+		fSys = k8s.MakeFsOnDisk()
+	}
+
+	client := backends.Kpt
+	if client == nil {
+		client = &kpt.CommandLine{FileSystem: fSys}
+	}
+
+	clusterFactory := backends.NewCluster
+	if clusterFactory == nil {
+		clusterFactory = k8s.NewCluster
+	}
+
+	provider := app.NewProvider(client, fSys, clusterFactory)
+	if backends.Logger.GetSink() != nil {
+		provider.SetLogger(backends.Logger)
+	}
+
+	return &Client{provider: provider, tracerProvider: backends.TracerProvider}
+}
+
+func (o CommonOptions) runnerOptions(tracerProvider trace.TracerProvider) *app.NephioRunnerOptions {
+	opts := &app.NephioRunnerOptions{
+		Cluster:          o.Cluster,
+		BasePath:         o.BasePath,
+		NephioRepoURI:    o.NephioRepoURI,
+		NephioRepoRef:    o.NephioRepoRef,
+		GitServiceURI:    o.GitServiceURI,
+		PatchesDir:       o.PatchesDir,
+		SkipVerify:       o.SkipVerify,
+		VerifyTimeout:    o.VerifyTimeout,
+		ReconcileTimeout: o.ReconcileTimeout,
+		Parallelism:      o.Parallelism,
+		RetryPolicies:    o.RetryPolicies,
+		CacheDir:         o.CacheDir,
+		Bundle:           o.Bundle,
+		ImageMirror:      o.ImageMirror,
+		LockFile:         o.LockFile,
+		Locked:           o.Locked,
+		Resume:           o.Resume,
+		ReportFile:       o.ReportFile,
+		JUnitFile:        o.JUnitFile,
+		WriteLogs:        o.WriteLogs,
+		Debug:            o.Debug,
+		TracerProvider:   tracerProvider,
+	}
+
+	if len(opts.NephioRepoURI) == 0 {
+		opts.NephioRepoURI = DefaultNephioRepoURI
+	}
+
+	if len(opts.GitServiceURI) == 0 {
+		opts.GitServiceURI = DefaultGitServiceURI
+	}
+
+	return opts
+}
+
+// Init installs the Nephio control plane (System and WebUI components) on the cluster.
+func (c *Client) Init(ctx context.Context, opts *InitOptions) error {
+	runnerOpts := opts.runnerOptions(c.tracerProvider)
+	runnerOpts.BackendBaseUrl = opts.BackendBaseURL
+	runnerOpts.WebUIClusterType = opts.WebUIClusterType
+
+	return c.provider.Init(ctx, runnerOpts)
+}
+
+// Join installs ConfigSync on the cluster and registers it in the management cluster.
+func (c *Client) Join(ctx context.Context, opts *JoinOptions) error {
+	runnerOpts := opts.runnerOptions(c.tracerProvider)
+	runnerOpts.MgmtKubeconfig = opts.Management.Kubeconfig
+	runnerOpts.MgmtContext = opts.Management.Context
+	runnerOpts.ClusterName = opts.ClusterName
+	runnerOpts.ClusterRegion = opts.ClusterRegion
+	runnerOpts.ClusterLabels = opts.ClusterLabels
+
+	return c.provider.Join(ctx, runnerOpts)
+}
+
+// Reset deletes the resources of the Nephio components from the cluster and removes their local
+// packages.
+func (c *Client) Reset(ctx context.Context, opts *ResetOptions) error {
+	return c.provider.Reset(ctx, &app.ResetOptions{
+		Cluster:      opts.Cluster,
+		BasePath:     opts.BasePath,
+		Phase:        opts.Phase,
+		KeepPackages: opts.KeepPackages,
+	})
+}
+
+// Status reports the state of the Nephio components and their resources.
+func (c *Client) Status(ctx context.Context, opts *StatusOptions) ([]ComponentStatus, error) {
+	return c.provider.Status(ctx, &app.StatusOptions{Cluster: opts.Cluster, BasePath: opts.BasePath})
+}
+
+// InstallState retrieves the components and customizations recorded in the cluster by the Init
+// and Join operations.
+func (c *Client) InstallState(ctx context.Context, opts *ClusterOptions) (*InstallState, error) {
+	return c.provider.InstallState(ctx, opts)
+}
@@ -0,0 +1,198 @@
+/*
+Copyright © 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nephioadm_test
+
+import (
+	"context"
+
+	"github.com/electrocucaracha/nephioadm/pkg/nephioadm"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// fakeKpt records the kpt commands and the local path they run on.
+type fakeKpt struct {
+	commands []string
+}
+
+var _ nephioadm.KptClient = (*fakeKpt)(nil)
+
+func (f *fakeKpt) run(opts *nephioadm.CommandOptions, command string) error {
+	f.commands = append(f.commands, command+" "+opts.Path)
+
+	return nil
+}
+
+func (f *fakeKpt) PkgGet(_ context.Context, opts *nephioadm.CommandOptions, pkg *nephioadm.Package) error {
+	return f.run(opts, "pkg get "+pkg.String())
+}
+
+func (f *fakeKpt) PkgTree(_ context.Context, opts *nephioadm.CommandOptions) error {
+	return f.run(opts, "pkg tree")
+}
+
+func (f *fakeKpt) PkgDiff(_ context.Context, opts *nephioadm.CommandOptions) error {
+	return f.run(opts, "pkg diff")
+}
+
+func (f *fakeKpt) FnRender(_ context.Context, opts *nephioadm.CommandOptions) error {
+	return f.run(opts, "fn render")
+}
+
+func (f *fakeKpt) FnEval(_ context.Context, opts *nephioadm.CommandOptions, _, _, _, _ string) error {
+	return f.run(opts, "fn eval")
+}
+
+func (f *fakeKpt) LiveInit(_ context.Context, opts *nephioadm.CommandOptions) error {
+	return f.run(opts, "live init")
+}
+
+func (f *fakeKpt) LiveApply(_ context.Context, opts *nephioadm.CommandOptions) error {
+	return f.run(opts, "live apply")
+}
+
+func (f *fakeKpt) LiveDestroy(_ context.Context, opts *nephioadm.CommandOptions) error {
+	return f.run(opts, "live destroy")
+}
+
+// fakeCluster reports every resource as not found.
+type fakeCluster struct{}
+
+func (fakeCluster) ApplyResource(context.Context, schema.GroupVersionResource, *unstructured.Unstructured) error {
+	return nil
+}
+
+func (fakeCluster) GetResource(_ context.Context, gvr schema.GroupVersionResource, _, name string,
+) (*unstructured.Unstructured, error) {
+	return nil, apierrors.NewNotFound(gvr.GroupResource(), name)
+}
+
+func (fakeCluster) ListResources(context.Context, schema.GroupVersionResource, string, string,
+) ([]unstructured.Unstructured, error) {
+	return nil, nil
+}
+
+func (fakeCluster) ResourceFor(gk schema.GroupKind) (schema.GroupVersionResource, error) {
+	return schema.GroupVersionResource{Group: gk.Group, Resource: gk.Kind}, nil
+}
+
+func (fakeCluster) GetPodLogs(context.Context, string, string, string) ([]byte, error) {
+	return nil, nil
+}
+
+func newFakeCluster(*nephioadm.ClusterOptions) (nephioadm.ClusterClient, error) {
+	return fakeCluster{}, nil
+}
+
+var _ = Describe("Client", func() {
+	var client *nephioadm.Client
+	var kpt *fakeKpt
+	var fSys filesys.FileSystem
+
+	BeforeEach(func() {
+		kpt = &fakeKpt{}
+		fSys = filesys.MakeFsInMemory()
+		client = nephioadm.New(nephioadm.Backends{Kpt: kpt, NewCluster: newFakeCluster, FileSystem: fSys})
+	})
+
+	It("should install the control plane packages with the default repository", func() {
+		Expect(client.Init(context.Background(), &nephioadm.InitOptions{
+			CommonOptions: nephioadm.CommonOptions{BasePath: "/tmp/nephio", SkipVerify: true},
+		})).To(Succeed())
+
+		Expect(fSys.Exists("/tmp/nephio/logs")).To(BeFalse())
+		Expect(kpt.commands).To(Equal([]string{
+			"pkg get " + nephioadm.DefaultNephioRepoURI + "/nephio-system /tmp/nephio/system",
+			"fn render /tmp/nephio/system",
+			"live init /tmp/nephio/system",
+			"live apply /tmp/nephio/system",
+			"pkg get " + nephioadm.DefaultNephioRepoURI + "/nephio-webui /tmp/nephio/webui",
+			"fn render /tmp/nephio/webui",
+			"live init /tmp/nephio/webui",
+			"live apply /tmp/nephio/webui",
+		}))
+	})
+
+	It("should use the cluster, the log files and the tracer provider requested", func() {
+		clusters := []nephioadm.ClusterOptions{}
+		recorder := tracetest.NewSpanRecorder()
+		client = nephioadm.New(nephioadm.Backends{
+			Kpt: kpt, FileSystem: fSys,
+			NewCluster: func(opts *nephioadm.ClusterOptions) (nephioadm.ClusterClient, error) {
+				clusters = append(clusters, *opts)
+
+				return newFakeCluster(opts)
+			},
+			TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+		})
+
+		Expect(client.Init(context.Background(), &nephioadm.InitOptions{
+			CommonOptions: nephioadm.CommonOptions{
+				Cluster:  nephioadm.ClusterOptions{Kubeconfig: "/tmp/kubeconfig", Context: "kind-edge"},
+				BasePath: "/tmp/nephio", SkipVerify: true, WriteLogs: true,
+			},
+		})).To(Succeed())
+		Expect(fSys.MkdirAll("/tmp/nephio/configsync")).To(Succeed())
+		Expect(client.Reset(context.Background(), &nephioadm.ResetOptions{
+			Cluster:  nephioadm.ClusterOptions{Context: "kind-edge"},
+			BasePath: "/tmp/nephio",
+		})).To(Succeed())
+
+		Expect(clusters).To(HaveEach(HaveField("Context", "kind-edge")))
+		Expect(clusters[0].Kubeconfig).To(Equal("/tmp/kubeconfig"))
+		Expect(fSys.ReadDir("/tmp/nephio/logs")).To(ConsistOf(HavePrefix("init-")))
+		Expect(recorder.Ended()).To(ContainElement(HaveField("Name()", "nephioadm init")))
+	})
+
+	It("should require a cluster name to register the workload cluster", func() {
+		Expect(client.Join(context.Background(), &nephioadm.JoinOptions{
+			Management: nephioadm.ClusterOptions{Context: "kind-mgmt"},
+		})).To(MatchError(ContainSubstring("a cluster name is required")))
+		Expect(kpt.commands).To(BeEmpty())
+	})
+
+	It("should stop when the context is cancelled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		Expect(client.Join(ctx, &nephioadm.JoinOptions{
+			CommonOptions: nephioadm.CommonOptions{BasePath: "/tmp/nephio", SkipVerify: true},
+		})).To(MatchError(ContainSubstring("context canceled")))
+		Expect(kpt.commands).To(BeEmpty())
+	})
+
+	It("should report and reset the fetched components", func() {
+		Expect(fSys.MkdirAll("/tmp/nephio/configsync")).To(Succeed())
+
+		results, err := client.Status(context.Background(), &nephioadm.StatusOptions{BasePath: "/tmp/nephio"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(3))
+		Expect(results[2].Name).To(Equal(nephioadm.ComponentConfigSync))
+		Expect(results[2].Fetched).To(BeTrue())
+		Expect(results[2].Ready()).To(BeFalse())
+
+		Expect(client.Reset(context.Background(), &nephioadm.ResetOptions{BasePath: "/tmp/nephio"})).To(Succeed())
+		Expect(kpt.commands).To(Equal([]string{"live destroy /tmp/nephio/configsync"}))
+		Expect(fSys.Exists("/tmp/nephio/configsync")).To(BeFalse())
+	})
+})
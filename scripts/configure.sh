@@ -22,7 +22,8 @@ source _common.sh
 trap get_status ERR
 
 base_path=/tmp/nephio_pkgs
-sudo mkdir -p "$base_path"/{mgmt,edge}
+reports_path=${REPORTS_PATH:-/tmp/nephio_reports}
+sudo mkdir -p "$base_path"/{mgmt,edge} "$reports_path"
 
 # Multi-cluster configuration
 if ! sudo docker ps --format "{{.Image}}" | grep -q "kindest/node"; then
@@ -40,6 +41,7 @@ pushd "$(git rev-parse --show-toplevel)" >/dev/null
 # Bootstrap management cluster
 sudo kubectl config use-context kind-nephio
 sudo "$(command -v go)" run ./... init --base-path "$base_path/mgmt" --debug \
+    --report "$reports_path/init.json" --junit "$reports_path/init.xml" \
     --git-service "http://$(ip route get 8.8.8.8 | grep '^8.' | awk '{ print $7 }'):3000/nephio-playground" \
     --backend-base-url "http://localhost:7007" \
     --webui-cluster-type NodePort
@@ -47,5 +49,6 @@ sudo "$(command -v go)" run ./... init --base-path "$base_path/mgmt" --debug \
 # Join additional clusters
 sudo kubectl config use-context kind-regional
 sudo "$(command -v go)" run ./... join --base-path "$base_path/edge" --debug \
+    --report "$reports_path/join.json" --junit "$reports_path/join.xml" \
     --git-service "http://$(ip route get 8.8.8.8 | grep '^8.' | awk '{ print $7 }'):3000/nephio-playground"
 popd >/dev/null
@@ -13,24 +13,23 @@ set -o errexit
 set -o nounset
 [[ ${DEBUG:-false} != "true" ]] || set -o xtrace
 
-# shellcheck source=./scripts/_assertions.sh
-source _assertions.sh
+# shellcheck source=./scripts/_utils.sh
+source _utils.sh
 
 # shellcheck source=./scripts/_common.sh
 source _common.sh
 
 trap get_status ERR
 
+# shellcheck disable=SC1091
+[ -f /etc/profile.d/path.sh ] && source /etc/profile.d/path.sh
+pushd "$(git rev-parse --show-toplevel)" >/dev/null
 for context in $(kubectl config get-contexts --no-headers --output name); do
-    kubectl config use-context "$context"
+    phase="join"
     if [[ $context == "kind-nephio"* ]]; then
-        info "Assert Nephio installation"
-        assert_contains "$(kubectl get deploy -n nephio-system -o jsonpath='{.items[*].metadata.name}')" package-deployment-controller
-
-        info "Assert Nephio UI installation"
-        assert_contains "$(kubectl get deploy -n nephio-webui -o jsonpath='{.items[*].metadata.name}')" nephio-webui
-    else
-        info "Assert Config Sync installation"
-        assert_contains "$(kubectl api-resources --api-group=configsync.gke.io)" reposyncs
+        phase="init"
     fi
+    info "Assert Nephio $phase installation on $context"
+    sudo "$(command -v go)" run ./... verify --context "$context" --phase "$phase"
 done
+popd >/dev/null